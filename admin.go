@@ -0,0 +1,56 @@
+package goauth
+
+import (
+	"strings"
+
+	"github.com/curtisnewbie/miso/miso"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// role nos of the administrators, the administrators have access to all resources
+	PropAdminRoleNos = "goauth.admin.role-nos"
+)
+
+var (
+	adminBypassCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "goauth_admin_bypass_total",
+		Help: "Number of resource checks bypassed for administrator roles",
+	}, []string{"role_no"})
+)
+
+func init() {
+	miso.SetDefProp(PropAdminRoleNos, []string{DefaultAdminRoleNo})
+	prometheus.MustRegister(adminBypassCounter)
+}
+
+// Role nos of the administrators configured
+func adminRoleNos() []string {
+	roleNos := []string{}
+	for _, r := range miso.GetPropStrSlice(PropAdminRoleNos) {
+		if r = strings.TrimSpace(r); r != "" {
+			roleNos = append(roleNos, r)
+		}
+	}
+	return roleNos
+}
+
+func isAdminRole(roleNo string) bool {
+	for _, r := range adminRoleNos() {
+		if r == roleNo {
+			return true
+		}
+	}
+	return false
+}
+
+// Record that the resource check is bypassed because the role is an administrator
+func markAdminBypass(rail miso.Rail, roleNo string, resCode string) {
+	adminBypassCounter.WithLabelValues(roleNo).Inc()
+	rail.Infof("Bypassed check of resource '%s', role '%s' is an administrator", resCode, roleNo)
+}
+
+// List administrator roles, the administrators have access to all resources
+func ListAdminRoles(rail miso.Rail) ([]RoleBrief, error) {
+	return listRoleBriefs(adminRoleNos())
+}
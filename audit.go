@@ -0,0 +1,362 @@
+package goauth
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/curtisnewbie/miso/miso"
+	"gorm.io/gorm"
+)
+
+const (
+	PropAuditEnabled         = "goauth.audit.enabled"
+	PropAuditSinks           = "goauth.audit.sinks"
+	PropAuditSamplePermitted = "goauth.audit.sampling.permitted"
+	PropAuditSampleForbidden = "goauth.audit.sampling.forbidden"
+	PropAuditBufferSize      = "goauth.audit.buffer-size"
+	PropAuditMySQLRetainDays = "goauth.audit.mysql.retain-days"
+	AuditSinkMySQL           = "mysql"
+	AuditSinkEventBus        = "eventbus"
+	AccessDecisionEventBus   = "goauth.access.decision"
+	auditFlushInterval       = time.Second
+	auditMaxBatchSize        = 200
+)
+
+// max length (in runes) of the columns in table access_decision
+const (
+	maxAccessDecisionRoleNosLen  = 255
+	maxAccessDecisionMethodLen   = 10
+	maxAccessDecisionUrlLen      = 128
+	maxAccessDecisionPathNoLen   = 32
+	maxAccessDecisionResCodesLen = 255
+	maxAccessDecisionReasonLen   = 255
+)
+
+func init() {
+	miso.SetDefProp(PropAuditEnabled, false)
+	miso.SetDefProp(PropAuditSinks, []string{AuditSinkMySQL})
+	miso.SetDefProp(PropAuditSamplePermitted, "0")
+	miso.SetDefProp(PropAuditSampleForbidden, "1")
+	miso.SetDefProp(PropAuditBufferSize, 10000)
+	miso.SetDefProp(PropAuditMySQLRetainDays, 7)
+}
+
+// Record of access decision made by TestResourceAccess
+type AccessDecision struct {
+	Time     time.Time `json:"time"`
+	RoleNos  string    `json:"roleNos"`  // role nos, joined with ','
+	Method   string    `json:"method"`   // http method normalized
+	Url      string    `json:"url"`      // url normalized
+	PathNo   string    `json:"pathNo"`   // path matched, empty if path not found
+	ResCodes string    `json:"resCodes"` // resources required by the path, joined with ','
+	Valid    bool      `json:"valid"`    // the decision
+	Reason   string    `json:"reason"`   // why the access is permitted or refused
+}
+
+// Sink of access decisions, decisions are written in batches asynchronously
+type DecisionSink interface {
+	Write(rail miso.Rail, records []AccessDecision) error
+}
+
+// Sink that writes decisions to table access_decision
+type MySQLDecisionSink struct{}
+
+func (s MySQLDecisionSink) Write(rail miso.Rail, records []AccessDecision) error {
+	rows := make([]EAccessDecision, 0, len(records))
+	for _, r := range records {
+		rows = append(rows, EAccessDecision{
+			Ctime:    r.Time,
+			RoleNos:  truncateRunes(r.RoleNos, maxAccessDecisionRoleNosLen),
+			Method:   truncateRunes(r.Method, maxAccessDecisionMethodLen),
+			Url:      truncateRunes(r.Url, maxAccessDecisionUrlLen),
+			PathNo:   truncateRunes(r.PathNo, maxAccessDecisionPathNoLen),
+			ResCodes: truncateRunes(r.ResCodes, maxAccessDecisionResCodesLen),
+			Valid:    r.Valid,
+			Reason:   truncateRunes(r.Reason, maxAccessDecisionReasonLen),
+		})
+	}
+	return miso.GetMySQL().Table("access_decision").Omit("Id").CreateInBatches(rows, auditMaxBatchSize).Error
+}
+
+// Truncate the string to at most n runes
+func truncateRunes(s string, n int) string {
+	if len(s) <= n { // at most n bytes, so at most n runes
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// Sink that publishes decisions to event bus AccessDecisionEventBus
+type EventBusDecisionSink struct{}
+
+func (s EventBusDecisionSink) Write(rail miso.Rail, records []AccessDecision) error {
+	for _, r := range records {
+		if e := miso.PubEventBus(rail, r, AccessDecisionEventBus); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+type EAccessDecision struct {
+	Id       int
+	Ctime    time.Time
+	RoleNos  string
+	Method   string
+	Url      string
+	PathNo   string
+	ResCodes string
+	Valid    bool
+	Reason   string
+}
+
+type auditor struct {
+	sinks           []DecisionSink
+	samplePermitted float64
+	sampleForbidden float64
+	records         chan AccessDecision
+	dropped         int64 // records dropped since last flush because the buffer is full, accessed atomically
+}
+
+var (
+	auditorMu  sync.RWMutex
+	curAuditor *auditor
+	extraSinks []DecisionSink
+)
+
+// Register additional sink of access decisions, it should be called before the server is bootstrapped
+func RegisterDecisionSink(sink DecisionSink) {
+	auditorMu.Lock()
+	defer auditorMu.Unlock()
+	extraSinks = append(extraSinks, sink)
+}
+
+// Start writing access decisions to the sinks configured, it's a no-op if 'goauth.audit.enabled' is false
+func BootstrapAuditor(rail miso.Rail) error {
+	if !miso.GetPropBool(PropAuditEnabled) {
+		return nil
+	}
+
+	auditorMu.Lock()
+	defer auditorMu.Unlock()
+
+	a := &auditor{
+		samplePermitted: parseSampleRate(rail, PropAuditSamplePermitted),
+		sampleForbidden: parseSampleRate(rail, PropAuditSampleForbidden),
+		records:         make(chan AccessDecision, miso.GetPropInt(PropAuditBufferSize)),
+	}
+	for _, s := range miso.GetPropStrSlice(PropAuditSinks) {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case AuditSinkMySQL:
+			a.sinks = append(a.sinks, MySQLDecisionSink{})
+		case AuditSinkEventBus:
+			if e := miso.NewEventBus(AccessDecisionEventBus); e != nil {
+				return e
+			}
+			a.sinks = append(a.sinks, EventBusDecisionSink{})
+		default:
+			return miso.NewErr("Unknown audit sink: " + s)
+		}
+	}
+	a.sinks = append(a.sinks, extraSinks...)
+
+	go a.run()
+	curAuditor = a
+	rail.Infof("Auditing access decisions, sinks: %v, sampling (permitted: %v, forbidden: %v)",
+		miso.GetPropStrSlice(PropAuditSinks), a.samplePermitted, a.sampleForbidden)
+	return nil
+}
+
+func parseSampleRate(rail miso.Rail, prop string) float64 {
+	v := strings.TrimSpace(miso.GetPropStr(prop))
+	if v == "" {
+		return 0
+	}
+	f, e := strconv.ParseFloat(v, 64)
+	if e != nil {
+		rail.Warnf("Invalid sampling rate for '%s': '%s', %v", prop, v, e)
+		return 0
+	}
+	return f
+}
+
+// Check whether the decision should be recorded, the rate is between 0 and 1
+func sampled(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+// Record the access decision asynchronously, it's a no-op if auditing is disabled or the decision is not sampled
+func auditDecision(rail miso.Rail, valid bool, build func() AccessDecision) {
+	auditorMu.RLock()
+	a := curAuditor
+	auditorMu.RUnlock()
+	if a == nil {
+		return
+	}
+
+	rate := a.sampleForbidden
+	if valid {
+		rate = a.samplePermitted
+	}
+	if !sampled(rate) {
+		return
+	}
+
+	r := build()
+	r.Time = time.Now()
+	r.Valid = valid
+
+	select {
+	case a.records <- r:
+	default:
+		atomic.AddInt64(&a.dropped, 1) // logged on flush, instead of once per record
+	}
+}
+
+func (a *auditor) run() {
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]AccessDecision, 0, auditMaxBatchSize)
+	flush := func() {
+		rail := miso.EmptyRail()
+		if n := atomic.SwapInt64(&a.dropped, 0); n > 0 {
+			rail.Warnf("Audit buffer is full, %d access decisions dropped", n)
+		}
+		if len(batch) < 1 {
+			return
+		}
+		for _, s := range a.sinks {
+			if e := s.Write(rail, batch); e != nil {
+				rail.Errorf("Failed to write %d access decisions to sink %T, %v", len(batch), s, e)
+			}
+		}
+		batch = make([]AccessDecision, 0, auditMaxBatchSize)
+	}
+
+	for {
+		select {
+		case r := <-a.records:
+			batch = append(batch, r)
+			if len(batch) >= auditMaxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+type ListDeniedDecisionReq struct {
+	RoleNo    string      `json:"roleNo"`    // role no, matched against any of the roles
+	Url       string      `json:"url"`       // fuzzy match
+	Method    string      `json:"method"`    // http method
+	PathNo    string      `json:"pathNo"`    // path matched
+	StartTime *miso.ETime `json:"startTime"` // optional, inclusive
+	EndTime   *miso.ETime `json:"endTime"`   // optional, exclusive
+	Paging    miso.Paging `json:"pagingVo"`
+}
+
+type ListedAccessDecision struct {
+	Id       int        `json:"id"`
+	Ctime    miso.ETime `json:"ctime"`
+	RoleNos  string     `json:"roleNos"`
+	Method   string     `json:"method"`
+	Url      string     `json:"url"`
+	PathNo   string     `json:"pathNo"`
+	ResCodes string     `json:"resCodes"`
+	Valid    bool       `json:"valid"`
+	Reason   string     `json:"reason"`
+}
+
+type ListDeniedDecisionResp struct {
+	Paging  miso.Paging            `json:"pagingVo"`
+	Payload []ListedAccessDecision `json:"payload"`
+}
+
+// Search recent denials recorded by MySQLDecisionSink
+func ListDeniedDecisions(ec miso.Rail, req ListDeniedDecisionReq) (ListDeniedDecisionResp, error) {
+
+	applyCond := func(t *gorm.DB) *gorm.DB {
+		t = t.Where("valid = 0")
+		if req.RoleNo != "" {
+			t = t.Where("FIND_IN_SET(?, role_nos) > 0", req.RoleNo)
+		}
+		if req.Url != "" {
+			t = t.Where("url LIKE ?", "%"+req.Url+"%")
+		}
+		if req.Method != "" {
+			t = t.Where("method = ?", strings.ToUpper(req.Method))
+		}
+		if req.PathNo != "" {
+			t = t.Where("path_no = ?", req.PathNo)
+		}
+		if req.StartTime != nil {
+			t = t.Where("ctime >= ?", time.Time(*req.StartTime))
+		}
+		if req.EndTime != nil {
+			t = t.Where("ctime < ?", time.Time(*req.EndTime))
+		}
+		return t
+	}
+
+	var decisions []ListedAccessDecision
+	tx := miso.GetMySQL().
+		Table("access_decision").
+		Order("id DESC")
+
+	tx = applyCond(tx).
+		Offset(req.Paging.GetOffset()).
+		Limit(req.Paging.GetLimit()).
+		Scan(&decisions)
+	if tx.Error != nil {
+		return ListDeniedDecisionResp{}, tx.Error
+	}
+	if decisions == nil {
+		decisions = []ListedAccessDecision{}
+	}
+
+	var count int
+	tx = miso.GetMySQL().
+		Table("access_decision").
+		Select("COUNT(*)")
+
+	tx = applyCond(tx).
+		Scan(&count)
+	if tx.Error != nil {
+		return ListDeniedDecisionResp{}, tx.Error
+	}
+
+	return ListDeniedDecisionResp{Payload: decisions, Paging: miso.Paging{Limit: req.Paging.Limit, Page: req.Paging.Page, Total: count}}, nil
+}
+
+// Remove access decisions that are older than 'goauth.audit.mysql.retain-days'
+func PurgeAccessDecisions(rail miso.Rail) error {
+	days := miso.GetPropInt(PropAuditMySQLRetainDays)
+	if days < 1 {
+		return nil
+	}
+	before := time.Now().AddDate(0, 0, -days)
+	tx := miso.GetMySQL().Exec(`delete from access_decision where ctime < ?`, before)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		rail.Infof("Purged %d access decisions recorded before %v", tx.RowsAffected, before)
+	}
+	return nil
+}
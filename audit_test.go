@@ -0,0 +1,37 @@
+package goauth
+
+import "testing"
+
+func TestSampled(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if sampled(0) {
+			t.Fatal("rate 0 should never be sampled")
+		}
+		if sampled(-1) {
+			t.Fatal("negative rate should never be sampled")
+		}
+		if !sampled(1) {
+			t.Fatal("rate 1 should always be sampled")
+		}
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	cases := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"abc", 3, "abc"},
+		{"abcd", 3, "abc"},
+		{"", 3, ""},
+		{"你好世界", 4, "你好世界"},
+		{"你好世界", 2, "你好"},
+		{"a你好", 2, "a你"},
+	}
+	for _, c := range cases {
+		if got := truncateRunes(c.s, c.n); got != c.want {
+			t.Fatalf("truncateRunes(%q, %d) = %q, want %q", c.s, c.n, got, c.want)
+		}
+	}
+}
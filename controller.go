@@ -3,6 +3,7 @@ package goauth
 import (
 	"strings"
 
+	"github.com/curtisnewbie/goauth/policy"
 	"github.com/curtisnewbie/gocommon/common"
 	"github.com/curtisnewbie/gocommon/goauth"
 	"github.com/curtisnewbie/miso/miso"
@@ -11,10 +12,14 @@ import (
 
 const (
 	ResourceManageResources = "manage-resources"
+
+	// header of the name of the service that calls the internal endpoints to report resources and paths
+	HeaderReportingService = "X-Goauth-Service"
 )
 
 var (
-	resourceAccessCheckHisto = miso.NewPromHisto("goauth_resource_access_check_duration")
+	resourceAccessCheckHisto      = miso.NewPromHisto("goauth_resource_access_check_duration")
+	batchResourceAccessCheckHisto = miso.NewPromHisto("goauth_batch_resource_access_check_duration")
 )
 
 type PathDoc struct {
@@ -33,6 +38,10 @@ func RegisterWebEndpoints(rail miso.Rail) error {
 			Desc("Admin add resource").
 			Resource(ResourceManageResources),
 
+		miso.IPost("/update", UpdateResourceEp).
+			Desc("Admin update resource").
+			Resource(ResourceManageResources),
+
 		miso.IPost("/remove", DeleteResourceEp).
 			Desc("Admin remove resource").
 			Resource(ResourceManageResources),
@@ -41,12 +50,24 @@ func RegisterWebEndpoints(rail miso.Rail) error {
 			Desc("List all resource candidates for role").
 			Resource(ResourceManageResources),
 
+		miso.Get("/brief/tree", ListResourceTreeEp).
+			Desc("List resources grouped by the dotted hierarchy of the codes, only the candidates for role are included if roleNo is present").
+			Resource(ResourceManageResources),
+
 		miso.IPost("/list", ListResourcesEp).
 			Desc("Admin list resources").
 			Resource(ResourceManageResources),
 
+		miso.Get("/orphan/report", ReportOrphansEp).
+			Desc("Admin report orphaned resources, unbound paths, empty roles and bindings referencing nonexistent resources").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/orphan/clean", CleanOrphansEp).
+			Desc("Admin delete orphaned resources and bindings referencing nonexistent resources").
+			Resource(ResourceManageResources),
+
 		miso.Get("/brief/user", ListAllResBriefsOfRoleEp).
-			Desc("List resources of current user").
+			Desc("List resources of current user, i.e., the union of resources of all the user's roles").
 			Public(),
 
 		miso.Get("/brief/all", ListAllResBriefsEp).
@@ -63,10 +84,42 @@ func RegisterWebEndpoints(rail miso.Rail) error {
 			Desc("Admin remove resource from role").
 			Resource(ResourceManageResources),
 
+		miso.IPost("/resource/bulk/add", BulkAddResToRoleEp).
+			Desc("Admin add resources to role in bulk").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/resource/bulk/remove", BulkRemoveResFromRoleEp).
+			Desc("Admin remove resources from role in bulk").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/resource/bulk/replace", ReplaceResOfRoleEp).
+			Desc("Admin replace resources of role").
+			Resource(ResourceManageResources),
+
 		miso.IPost("/add", AddRoleEp).
 			Desc("Admin add role").
 			Resource(ResourceManageResources),
 
+		miso.Get("/admin/list", ListAdminRolesEp).
+			Desc("Admin list administrator roles").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/diff", DiffRolesEp).
+			Desc("Admin compare resources of two roles").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/clone", CloneRoleEp).
+			Desc("Admin clone role with all its resources").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/update", UpdateRoleEp).
+			Desc("Admin update role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/delete", DeleteRoleEp).
+			Desc("Admin delete role").
+			Resource(ResourceManageResources),
+
 		miso.IPost("/list", ListRolesEp).
 			Desc("Admin list roles").
 			Resource(ResourceManageResources),
@@ -82,6 +135,50 @@ func RegisterWebEndpoints(rail miso.Rail) error {
 		miso.IPost("/info", GetRoleInfoEp).
 			Desc("Get role info").
 			Public(),
+
+		miso.IPost("/parent/add", AddRoleParentEp).
+			Desc("Admin add parent role to role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/parent/remove", RemoveRoleParentEp).
+			Desc("Admin remove parent role from role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/parent/list", ListRoleParentsEp).
+			Desc("Admin list parent roles and ancestors of role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/deny/add", AddRoleDenyEp).
+			Desc("Admin add deny rule of resource or path to role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/deny/remove", RemoveRoleDenyEp).
+			Desc("Admin remove deny rule from role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/deny/list", ListRoleDeniesEp).
+			Desc("Admin list deny rules of role").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/access/explain", ExplainResourceAccessEp).
+			Desc("Admin explain how the access decision is made for roles, url and method").
+			Resource(ResourceManageResources),
+	)
+
+	miso.BaseRoute("/open/api/access").Group(
+		miso.IPost("/decision/denied/list", ListDeniedDecisionsEp).
+			Desc("Admin list recent access decisions that are denied").
+			Resource(ResourceManageResources),
+	)
+
+	miso.BaseRoute("/open/api/trash").Group(
+		miso.IPost("/list", ListTrashEp).
+			Desc("Admin list paths, resources or roles deleted").
+			Resource(ResourceManageResources),
+
+		miso.IPost("/restore", RestoreTrashEp).
+			Desc("Admin restore path, resource or role deleted").
+			Resource(ResourceManageResources),
 	)
 
 	miso.BaseRoute("/open/api/path").Group(
@@ -112,7 +209,7 @@ func RegisterWebEndpoints(rail miso.Rail) error {
 		miso.IPost("/resource/add",
 			func(c *gin.Context, rail miso.Rail, req CreateResReq) (any, error) {
 				user := common.GetUser(rail)
-				return nil, CreateResourceIfNotExist(rail, req, user)
+				return nil, CreateResourceIfNotExist(rail, req, c.GetHeader(HeaderReportingService), user)
 			}),
 		miso.IPost("/path/resource/access-test",
 			func(c *gin.Context, rail miso.Rail, req TestResAccessReq) (any, error) {
@@ -121,15 +218,34 @@ func RegisterWebEndpoints(rail miso.Rail) error {
 
 				return TestResourceAccess(rail, req)
 			}),
+		miso.IPost("/path/resource/access-test/batch",
+			func(c *gin.Context, rail miso.Rail, req BatchTestResAccessReq) (any, error) {
+				timer := miso.NewHistTimer(batchResourceAccessCheckHisto)
+				defer timer.ObserveDuration()
+
+				return BatchTestResourceAccess(rail, req)
+			}),
 		miso.IPost("/path/add",
 			func(c *gin.Context, rail miso.Rail, req CreatePathReq) (any, error) {
 				user := common.GetUser(rail)
-				return nil, CreatePathIfNotExist(rail, req, user)
+				return nil, CreatePathIfNotExist(rail, req, c.GetHeader(HeaderReportingService), user)
 			}),
 		miso.IPost("/role/info",
 			func(c *gin.Context, rail miso.Rail, req RoleInfoReq) (any, error) {
 				return GetRoleInfo(rail, req)
 			}),
+		miso.Get("/policy/snapshot",
+			func(c *gin.Context, rail miso.Rail) (any, error) {
+				return PolicySnapshot(rail)
+			}),
+		miso.Get("/policy/path",
+			func(c *gin.Context, rail miso.Rail) (any, error) {
+				return LoadPolicyPath(rail, c.Query("pathNo"))
+			}),
+		miso.Get("/policy/role",
+			func(c *gin.Context, rail miso.Rail) (any, error) {
+				return LoadPolicyRole(rail, c.Query("roleNo"))
+			}),
 	)
 	return nil
 }
@@ -139,7 +255,7 @@ func ListAllResBriefsOfRoleEp(c *gin.Context, ec miso.Rail) (any, error) {
 	if u.IsNil {
 		return []ResBrief{}, nil
 	}
-	return ListAllResBriefsOfRole(ec, u.RoleNo)
+	return ListAllResBriefsOfRoles(ec, policy.ResolveRoleNos(u.RoleNo, nil))
 }
 
 func ListAllResBriefsEp(c *gin.Context, ec miso.Rail) (any, error) {
@@ -152,7 +268,12 @@ func GetRoleInfoEp(c *gin.Context, ec miso.Rail, req RoleInfoReq) (any, error) {
 
 func CreateResourceIfNotExistEp(c *gin.Context, ec miso.Rail, req CreateResReq) (any, error) {
 	user := common.GetUser(ec)
-	return nil, CreateResourceIfNotExist(ec, req, user)
+	return nil, CreateResourceIfNotExist(ec, req, "", user)
+}
+
+func UpdateResourceEp(c *gin.Context, ec miso.Rail, req UpdateResReq) (any, error) {
+	user := common.GetUser(ec)
+	return nil, UpdateResource(ec, req, user)
 }
 
 func DeleteResourceEp(c *gin.Context, ec miso.Rail, req DeleteResourceReq) (any, error) {
@@ -164,6 +285,19 @@ func ListResourceCandidatesForRoleEp(c *gin.Context, ec miso.Rail) (any, error)
 	return ListResourceCandidatesForRole(ec, roleNo)
 }
 
+func ListResourceTreeEp(c *gin.Context, ec miso.Rail) (any, error) {
+	roleNo := c.Query("roleNo")
+	return ListResourceTree(ec, roleNo)
+}
+
+func ReportOrphansEp(c *gin.Context, ec miso.Rail) (any, error) {
+	return ReportOrphans(ec)
+}
+
+func CleanOrphansEp(c *gin.Context, ec miso.Rail, req CleanOrphansReq) (any, error) {
+	return CleanOrphans(ec, req)
+}
+
 func ListResourcesEp(c *gin.Context, ec miso.Rail, req ListResReq) (any, error) {
 	return ListResources(ec, req)
 }
@@ -177,11 +311,48 @@ func RemoveResFromRoleEp(c *gin.Context, ec miso.Rail, req RemoveRoleResReq) (an
 	return nil, RemoveResFromRole(ec, req)
 }
 
+func BulkAddResToRoleEp(c *gin.Context, ec miso.Rail, req BulkRoleResReq) (any, error) {
+	user := common.GetUser(ec)
+	return BulkAddResToRole(ec, req, user)
+}
+
+func BulkRemoveResFromRoleEp(c *gin.Context, ec miso.Rail, req BulkRoleResReq) (any, error) {
+	user := common.GetUser(ec)
+	return BulkRemoveResFromRole(ec, req, user)
+}
+
+func ReplaceResOfRoleEp(c *gin.Context, ec miso.Rail, req BulkRoleResReq) (any, error) {
+	user := common.GetUser(ec)
+	return ReplaceResOfRole(ec, req, user)
+}
+
 func AddRoleEp(c *gin.Context, ec miso.Rail, req AddRoleReq) (any, error) {
 	user := common.GetUser(ec)
 	return nil, AddRole(ec, req, user)
 }
 
+func ListAdminRolesEp(c *gin.Context, ec miso.Rail) (any, error) {
+	return ListAdminRoles(ec)
+}
+
+func DiffRolesEp(c *gin.Context, ec miso.Rail, req DiffRoleReq) (any, error) {
+	return DiffRoles(ec, req)
+}
+
+func CloneRoleEp(c *gin.Context, ec miso.Rail, req CloneRoleReq) (any, error) {
+	user := common.GetUser(ec)
+	return CloneRole(ec, req, user)
+}
+
+func UpdateRoleEp(c *gin.Context, ec miso.Rail, req UpdateRoleReq) (any, error) {
+	user := common.GetUser(ec)
+	return nil, UpdateRole(ec, req, user)
+}
+
+func DeleteRoleEp(c *gin.Context, ec miso.Rail, req DeleteRoleReq) (any, error) {
+	return nil, DeleteRole(ec, req)
+}
+
 func ListRolesEp(c *gin.Context, ec miso.Rail, req ListRoleReq) (any, error) {
 	return ListRoles(ec, req)
 }
@@ -194,6 +365,48 @@ func ListRoleResEp(c *gin.Context, ec miso.Rail, req ListRoleResReq) (any, error
 	return ListRoleRes(ec, req)
 }
 
+func AddRoleParentEp(c *gin.Context, ec miso.Rail, req AddRoleParentReq) (any, error) {
+	user := common.GetUser(ec)
+	return nil, AddRoleParent(ec, req, user)
+}
+
+func RemoveRoleParentEp(c *gin.Context, ec miso.Rail, req RemoveRoleParentReq) (any, error) {
+	return nil, RemoveRoleParent(ec, req)
+}
+
+func ListRoleParentsEp(c *gin.Context, ec miso.Rail, req ListRoleParentReq) (any, error) {
+	return ListRoleParents(ec, req)
+}
+
+func AddRoleDenyEp(c *gin.Context, ec miso.Rail, req AddRoleDenyReq) (any, error) {
+	user := common.GetUser(ec)
+	return nil, AddRoleDeny(ec, req, user)
+}
+
+func RemoveRoleDenyEp(c *gin.Context, ec miso.Rail, req RemoveRoleDenyReq) (any, error) {
+	return nil, RemoveRoleDeny(ec, req)
+}
+
+func ListRoleDeniesEp(c *gin.Context, ec miso.Rail, req ListRoleDenyReq) (any, error) {
+	return ListRoleDenies(ec, req)
+}
+
+func ExplainResourceAccessEp(c *gin.Context, ec miso.Rail, req TestResAccessReq) (any, error) {
+	return ExplainResourceAccess(ec, req)
+}
+
+func ListDeniedDecisionsEp(c *gin.Context, ec miso.Rail, req ListDeniedDecisionReq) (any, error) {
+	return ListDeniedDecisions(ec, req)
+}
+
+func ListTrashEp(c *gin.Context, ec miso.Rail, req ListTrashReq) (any, error) {
+	return ListTrash(ec, req)
+}
+
+func RestoreTrashEp(c *gin.Context, ec miso.Rail, req RestoreTrashReq) (any, error) {
+	return nil, RestoreTrash(ec, req)
+}
+
 func ListPathsEp(c *gin.Context, ec miso.Rail, req ListPathReq) (any, error) {
 	return ListPaths(ec, req)
 }
@@ -211,7 +424,7 @@ func DeletePathEp(c *gin.Context, ec miso.Rail, req DeletePathReq) (any, error)
 }
 
 func UpdatePathEp(c *gin.Context, ec miso.Rail, req UpdatePathReq) (any, error) {
-	return nil, UpdatePath(ec, req)
+	return UpdatePath(ec, req)
 }
 
 func RegisterInternalPathResourcesOnBootstrapped() {
@@ -228,7 +441,7 @@ func RegisterInternalPathResourcesOnBootstrapped() {
 			if res.Code == "" || res.Name == "" {
 				continue
 			}
-			if e := CreateResourceIfNotExist(rail, CreateResReq(res), user); e != nil {
+			if e := CreateResourceIfNotExist(rail, CreateResReq(res), app, user); e != nil {
 				return e
 			}
 		}
@@ -256,7 +469,7 @@ func RegisterInternalPathResourcesOnBootstrapped() {
 				Desc:    route.Desc,
 				ResCode: route.Resource,
 			}
-			if err := CreatePathIfNotExist(rail, r, user); err != nil {
+			if err := CreatePathIfNotExist(rail, r, app, user); err != nil {
 				return err
 			}
 		}
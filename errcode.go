@@ -1,5 +1,10 @@
 package goauth
 
 const (
-	ErrCodeRoleNotFound = "GA0001"
+	ErrCodeRoleNotFound      = "GA0001"
+	ErrCodePathNotFound      = "GA0002"
+	ErrCodeResourceNotFound  = "GA0003"
+	ErrCodeTrashItemNotFound = "GA0004"
+	ErrCodeIllegalArgument   = "GA0005" // the request is invalid
+	ErrCodeIllegalState      = "GA0006" // the request is valid, but conflicts with the current state, e.g., path already exists
 )
@@ -1,6 +1,7 @@
 package goauth
 
 import (
+	"github.com/curtisnewbie/goauth/policy"
 	"github.com/curtisnewbie/gocommon/common"
 	"github.com/curtisnewbie/miso/miso"
 )
@@ -21,15 +22,29 @@ func SubEventBus(rail miso.Rail) error {
 	miso.SubEventBus(addResourceEventBus, 2, ListenAddResourceEvent)
 	miso.SubEventBus(addResourceEventBusV2, 2, ListenAddResourceEvent)
 
+	// fanout exchange to notify in-process evaluators about the changes of policy
+	policy.DeclareChangeExchange()
 	return nil
 }
 
-func ListenAddResourceEvent(rail miso.Rail, req CreateResReq) error {
-	rail.Debugf("receive %+v", req)
-	return CreateResourceIfNotExist(rail, req, common.NilUser())
+// Resource reported via event bus
+type AddResourceEvent struct {
+	CreateResReq
+	Service string `json:"service"` // optional, service that reports the resource
 }
 
-func ListenAddPathEvent(rail miso.Rail, req CreatePathReq) error {
-	rail.Debugf("receive %+v", req)
-	return CreatePathIfNotExist(rail, req, common.NilUser())
+// Path reported via event bus
+type AddPathEvent struct {
+	CreatePathReq
+	Service string `json:"service"` // optional, service that reports the path, the path group is used if it's empty
+}
+
+func ListenAddResourceEvent(rail miso.Rail, evt AddResourceEvent) error {
+	rail.Debugf("receive %+v", evt)
+	return CreateResourceIfNotExist(rail, evt.CreateResReq, evt.Service, common.NilUser())
+}
+
+func ListenAddPathEvent(rail miso.Rail, evt AddPathEvent) error {
+	rail.Debugf("receive %+v", evt)
+	return CreatePathIfNotExist(rail, evt.CreatePathReq, evt.Service, common.NilUser())
 }
@@ -0,0 +1,128 @@
+package goauth
+
+import (
+	"fmt"
+
+	"github.com/curtisnewbie/miso/miso"
+)
+
+const (
+	TraceSrcCache = "CACHE" // served from cache
+	TraceSrcMySQL = "MYSQL" // loaded from MySQL
+	TraceSrcNone  = "NONE"  // neither cache nor MySQL is involved
+)
+
+type AccessStep struct {
+	Step   string `json:"step"`   // name of the step
+	Source string `json:"source"` // where the data is served from: CACHE, MYSQL, NONE
+	Detail string `json:"detail"` // what happened in the step
+}
+
+type ResGrant struct {
+	ResCode string `json:"resCode"` // resource code
+	RoleNo  string `json:"roleNo"`  // the role that grants the resource, empty if none of the roles grants the resource
+	Denied  bool   `json:"denied"`  // whether the resource is denied
+}
+
+// Explanation of the access decision made by TestResourceAccess
+type ExplainResAccessResp struct {
+	Valid      bool         `json:"valid"`      // the decision
+	Reason     string       `json:"reason"`     // why the access is permitted or refused
+	Url        string       `json:"url"`        // url normalized by policy.PreprocessUrl
+	Method     string       `json:"method"`     // http method normalized
+	RoleNos    []string     `json:"roleNos"`    // role nos resolved
+	PathNo     string       `json:"pathNo"`     // path matched
+	MatchedUrl string       `json:"matchedUrl"` // url (or url pattern) of the path matched
+	Ptype      PathType     `json:"ptype"`      // type of the path matched
+	ResCodes   []string     `json:"resCodes"`   // resources required by the path
+	ResMode    PathResMode  `json:"resMode"`    // resource mode of the path
+	Grants     []ResGrant   `json:"grants"`     // how each of the required resources is granted
+	Steps      []AccessStep `json:"steps"`      // steps of the decision
+}
+
+// Record a step, it's a no-op if ex is nil
+func (ex *ExplainResAccessResp) step(step string, source string, detail string, args ...any) {
+	if ex == nil {
+		return
+	}
+	ex.Steps = append(ex.Steps, AccessStep{Step: step, Source: source, Detail: fmt.Sprintf(detail, args...)})
+}
+
+// Record the reason of the decision, it's a no-op if ex is nil
+func (ex *ExplainResAccessResp) reason(reason string, args ...any) {
+	if ex == nil {
+		return
+	}
+	ex.Reason = fmt.Sprintf(reason, args...)
+}
+
+// Record how the resource is granted, it's a no-op if ex is nil
+func (ex *ExplainResAccessResp) grant(g ResGrant) {
+	if ex == nil {
+		return
+	}
+	ex.Grants = append(ex.Grants, g)
+}
+
+// Record the path matched, it's a no-op if ex is nil
+func (ex *ExplainResAccessResp) matched(cur CachedUrlRes) {
+	if ex == nil {
+		return
+	}
+	ex.PathNo = cur.PathNo
+	ex.MatchedUrl = cur.Url
+	ex.Ptype = cur.Ptype
+	ex.ResCodes = cur.ResCodes
+	ex.ResMode = cur.ResMode
+}
+
+// Check where the data will be served from before it's loaded, only checked when ex is not nil
+func (ex *ExplainResAccessResp) source(rail miso.Rail, exists func() (bool, error)) string {
+	if ex == nil {
+		return ""
+	}
+	ok, e := exists()
+	if e != nil {
+		rail.Warnf("Failed to check cache, %v", e)
+		return TraceSrcMySQL
+	}
+	if ok {
+		return TraceSrcCache
+	}
+	return TraceSrcMySQL
+}
+
+// Explain how the access decision is made, the decision is the same as the one made by TestResourceAccess
+func ExplainResourceAccess(rail miso.Rail, req TestResAccessReq) (ExplainResAccessResp, error) {
+	ex := &ExplainResAccessResp{
+		RoleNos:  []string{},
+		ResCodes: []string{},
+		Grants:   []ResGrant{},
+		Steps:    []AccessStep{},
+	}
+	r, e := testResourceAccess(rail, req, ex, nil)
+	if e != nil {
+		return ExplainResAccessResp{}, e
+	}
+	ex.Valid = r.Valid
+	return *ex, nil
+}
+
+// Explain why the path is not found in cache
+func explainPathNotFound(ex *ExplainResAccessResp, url string, method string) {
+	if ex == nil {
+		return
+	}
+
+	var pathNo string
+	tx := miso.GetMySQL().Raw(`select path_no from path where method = ? and url = ? and is_del = 0 limit 1`, method, url).Scan(&pathNo)
+	if tx.Error != nil {
+		ex.step("lookup path", TraceSrcMySQL, "failed to lookup path, %v", tx.Error)
+		return
+	}
+	if pathNo != "" {
+		ex.step("lookup path", TraceSrcMySQL, "path '%s' is found in MySQL, but it's not cached yet", pathNo)
+		return
+	}
+	ex.step("lookup path", TraceSrcMySQL, "path is not found in MySQL")
+}
@@ -0,0 +1,87 @@
+package goauth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/curtisnewbie/gocommon/common"
+	"github.com/curtisnewbie/miso/miso"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func hasStep(ex ExplainResAccessResp, step string, detail string) bool {
+	for _, s := range ex.Steps {
+		if s.Step == step && strings.Contains(s.Detail, detail) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExplainResourceAccess(t *testing.T) {
+	before(t)
+	rail := miso.EmptyRail()
+
+	resCode := miso.GenIdP("test_res_")
+	if e := CreateResourceIfNotExist(rail, CreateResReq{Name: "Test Explain", Code: resCode}, "", common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+	defer DeleteResource(rail, DeleteResourceReq{ResCode: resCode})
+
+	url := "/goauth/test/explain/" + resCode
+	if e := CreatePathIfNotExist(rail, CreatePathReq{Type: PtProtected, Url: url, Group: "goauth", Method: "GET", ResCode: resCode}, "", common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+	pathNo := genPathNo("goauth", url, "GET")
+	defer DeletePath(rail, DeletePathReq{PathNo: pathNo})
+	if e := LoadPathResCache(rail); e != nil {
+		t.Fatal(e)
+	}
+
+	// path not found
+	ex, e := ExplainResourceAccess(rail, TestResAccessReq{RoleNo: DefaultAdminRoleNo, Url: url + "/not/exist", Method: "GET"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if ex.Valid || ex.Reason != "path not found" || !hasStep(ex, "lookup path", "path is not found in MySQL") {
+		t.Fatalf("path should not be found, %+v", ex)
+	}
+
+	// admin bypass, explaining is not counted as a real bypass
+	bypassed := testutil.ToFloat64(adminBypassCounter.WithLabelValues(DefaultAdminRoleNo))
+	ex, e = ExplainResourceAccess(rail, TestResAccessReq{RoleNo: DefaultAdminRoleNo, Url: url, Method: "GET"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !ex.Valid || ex.PathNo != pathNo || !hasStep(ex, "check resource", "is the administrator") {
+		t.Fatalf("administrator should bypass the check, %+v", ex)
+	}
+	if v := testutil.ToFloat64(adminBypassCounter.WithLabelValues(DefaultAdminRoleNo)); v != bypassed {
+		t.Fatalf("explaining should not be counted as admin bypass, %v -> %v", bypassed, v)
+	}
+
+	// deny
+	roleName := miso.GenIdP("deny_")
+	if e := AddRole(rail, AddRoleReq{Name: roleName}, common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+	var roleNo string
+	if tx := miso.GetMySQL().Raw(`select role_no from role where name = ? and is_del = 0`, roleName).Scan(&roleNo); tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	defer DeleteRole(rail, DeleteRoleReq{RoleNo: roleNo})
+	if e := AddResToRoleIfNotExist(rail, AddRoleResReq{RoleNo: roleNo, ResCode: resCode}, common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+	if e := AddRoleDeny(rail, AddRoleDenyReq{RoleNo: roleNo, PathNo: pathNo}, common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+
+	ex, e = ExplainResourceAccess(rail, TestResAccessReq{RoleNo: roleNo, Url: url, Method: "GET"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if ex.Valid || !strings.Contains(ex.Reason, "is denied") || !hasStep(ex, "load deny rules", pathNo) {
+		t.Fatalf("path should be denied, %+v", ex)
+	}
+}
@@ -0,0 +1,991 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: goauth.proto
+
+package goauthpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TestResAccessReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoleNo  string   `protobuf:"bytes,1,opt,name=role_no,json=roleNo,proto3" json:"role_no,omitempty"`    // role no, multiple role nos can be joined with ','
+	RoleNos []string `protobuf:"bytes,2,rep,name=role_nos,json=roleNos,proto3" json:"role_nos,omitempty"` // role nos, merged with role_no
+	Url     string   `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Method  string   `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+}
+
+func (x *TestResAccessReq) Reset() {
+	*x = TestResAccessReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestResAccessReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestResAccessReq) ProtoMessage() {}
+
+func (x *TestResAccessReq) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestResAccessReq.ProtoReflect.Descriptor instead.
+func (*TestResAccessReq) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TestResAccessReq) GetRoleNo() string {
+	if x != nil {
+		return x.RoleNo
+	}
+	return ""
+}
+
+func (x *TestResAccessReq) GetRoleNos() []string {
+	if x != nil {
+		return x.RoleNos
+	}
+	return nil
+}
+
+func (x *TestResAccessReq) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *TestResAccessReq) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+type TestResAccessResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+}
+
+func (x *TestResAccessResp) Reset() {
+	*x = TestResAccessResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestResAccessResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestResAccessResp) ProtoMessage() {}
+
+func (x *TestResAccessResp) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestResAccessResp.ProtoReflect.Descriptor instead.
+func (*TestResAccessResp) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TestResAccessResp) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+type BatchTestResAccessReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoleNo  string               `protobuf:"bytes,1,opt,name=role_no,json=roleNo,proto3" json:"role_no,omitempty"`    // role no, multiple role nos can be joined with ','
+	RoleNos []string             `protobuf:"bytes,2,rep,name=role_nos,json=roleNos,proto3" json:"role_nos,omitempty"` // role nos, merged with role_no
+	Items   []*ResAccessTestItem `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *BatchTestResAccessReq) Reset() {
+	*x = BatchTestResAccessReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchTestResAccessReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchTestResAccessReq) ProtoMessage() {}
+
+func (x *BatchTestResAccessReq) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchTestResAccessReq.ProtoReflect.Descriptor instead.
+func (*BatchTestResAccessReq) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchTestResAccessReq) GetRoleNo() string {
+	if x != nil {
+		return x.RoleNo
+	}
+	return ""
+}
+
+func (x *BatchTestResAccessReq) GetRoleNos() []string {
+	if x != nil {
+		return x.RoleNos
+	}
+	return nil
+}
+
+func (x *BatchTestResAccessReq) GetItems() []*ResAccessTestItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ResAccessTestItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Url    string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+}
+
+func (x *ResAccessTestItem) Reset() {
+	*x = ResAccessTestItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResAccessTestItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResAccessTestItem) ProtoMessage() {}
+
+func (x *ResAccessTestItem) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResAccessTestItem.ProtoReflect.Descriptor instead.
+func (*ResAccessTestItem) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResAccessTestItem) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResAccessTestItem) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+type BatchTestResAccessResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*ResAccessTestResult `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // decisions, in the same order as the items requested
+}
+
+func (x *BatchTestResAccessResp) Reset() {
+	*x = BatchTestResAccessResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchTestResAccessResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchTestResAccessResp) ProtoMessage() {}
+
+func (x *BatchTestResAccessResp) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchTestResAccessResp.ProtoReflect.Descriptor instead.
+func (*BatchTestResAccessResp) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchTestResAccessResp) GetItems() []*ResAccessTestResult {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ResAccessTestResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Url    string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Valid  bool   `protobuf:"varint,3,opt,name=valid,proto3" json:"valid,omitempty"`
+}
+
+func (x *ResAccessTestResult) Reset() {
+	*x = ResAccessTestResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResAccessTestResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResAccessTestResult) ProtoMessage() {}
+
+func (x *ResAccessTestResult) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResAccessTestResult.ProtoReflect.Descriptor instead.
+func (*ResAccessTestResult) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ResAccessTestResult) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResAccessTestResult) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ResAccessTestResult) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+type CreatePathReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type    string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // path type: PROTECTED, PUBLIC
+	Url     string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Group   string `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	Method  string `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Desc    string `protobuf:"bytes,5,opt,name=desc,proto3" json:"desc,omitempty"`
+	ResCode string `protobuf:"bytes,6,opt,name=res_code,json=resCode,proto3" json:"res_code,omitempty"`
+}
+
+func (x *CreatePathReq) Reset() {
+	*x = CreatePathReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreatePathReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePathReq) ProtoMessage() {}
+
+func (x *CreatePathReq) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePathReq.ProtoReflect.Descriptor instead.
+func (*CreatePathReq) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreatePathReq) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreatePathReq) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *CreatePathReq) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *CreatePathReq) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *CreatePathReq) GetDesc() string {
+	if x != nil {
+		return x.Desc
+	}
+	return ""
+}
+
+func (x *CreatePathReq) GetResCode() string {
+	if x != nil {
+		return x.ResCode
+	}
+	return ""
+}
+
+type CreatePathResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CreatePathResp) Reset() {
+	*x = CreatePathResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreatePathResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePathResp) ProtoMessage() {}
+
+func (x *CreatePathResp) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePathResp.ProtoReflect.Descriptor instead.
+func (*CreatePathResp) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{7}
+}
+
+type CreateResReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Code string `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *CreateResReq) Reset() {
+	*x = CreateResReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateResReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateResReq) ProtoMessage() {}
+
+func (x *CreateResReq) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateResReq.ProtoReflect.Descriptor instead.
+func (*CreateResReq) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateResReq) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateResReq) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type CreateResResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CreateResResp) Reset() {
+	*x = CreateResResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateResResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateResResp) ProtoMessage() {}
+
+func (x *CreateResResp) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateResResp.ProtoReflect.Descriptor instead.
+func (*CreateResResp) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{9}
+}
+
+type RoleInfoReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoleNo string `protobuf:"bytes,1,opt,name=role_no,json=roleNo,proto3" json:"role_no,omitempty"`
+}
+
+func (x *RoleInfoReq) Reset() {
+	*x = RoleInfoReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoleInfoReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoleInfoReq) ProtoMessage() {}
+
+func (x *RoleInfoReq) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoleInfoReq.ProtoReflect.Descriptor instead.
+func (*RoleInfoReq) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RoleInfoReq) GetRoleNo() string {
+	if x != nil {
+		return x.RoleNo
+	}
+	return ""
+}
+
+type RoleInfoResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RoleNo string `protobuf:"bytes,1,opt,name=role_no,json=roleNo,proto3" json:"role_no,omitempty"`
+	Name   string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *RoleInfoResp) Reset() {
+	*x = RoleInfoResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_goauth_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RoleInfoResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoleInfoResp) ProtoMessage() {}
+
+func (x *RoleInfoResp) ProtoReflect() protoreflect.Message {
+	mi := &file_goauth_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoleInfoResp.ProtoReflect.Descriptor instead.
+func (*RoleInfoResp) Descriptor() ([]byte, []int) {
+	return file_goauth_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RoleInfoResp) GetRoleNo() string {
+	if x != nil {
+		return x.RoleNo
+	}
+	return ""
+}
+
+func (x *RoleInfoResp) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+var File_goauth_proto protoreflect.FileDescriptor
+
+var file_goauth_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06,
+	0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x22, 0x70, 0x0a, 0x10, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65,
+	0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f,
+	0x6c, 0x65, 0x5f, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6c,
+	0x65, 0x4e, 0x6f, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x6e, 0x6f, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x6f, 0x6c, 0x65, 0x4e, 0x6f, 0x73, 0x12, 0x10,
+	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x22, 0x29, 0x0a, 0x11, 0x54, 0x65, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x69, 0x64, 0x22, 0x7c, 0x0a, 0x15, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54, 0x65, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x12, 0x17, 0x0a, 0x07,
+	0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x6f, 0x6c, 0x65, 0x4e, 0x6f, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x6e, 0x6f,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x6f, 0x6c, 0x65, 0x4e, 0x6f, 0x73,
+	0x12, 0x2f, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65,
+	0x73, 0x73, 0x54, 0x65, 0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x22, 0x3d, 0x0a, 0x11, 0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x65,
+	0x73, 0x74, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x22, 0x4b, 0x0a, 0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x31, 0x0a, 0x05, 0x69, 0x74,
+	0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x61, 0x75,
+	0x74, 0x68, 0x2e, 0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x65, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x55, 0x0a,
+	0x13, 0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x22, 0x92, 0x01, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50,
+	0x61, 0x74, 0x68, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x65,
+	0x73, 0x63, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x65, 0x73, 0x63, 0x12, 0x19,
+	0x0a, 0x08, 0x72, 0x65, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x72, 0x65, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x22, 0x10, 0x0a, 0x0e, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x22, 0x36, 0x0a, 0x0c, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x22, 0x0f, 0x0a, 0x0d, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x22, 0x26, 0x0a, 0x0b, 0x52, 0x6f, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f,
+	0x52, 0x65, 0x71, 0x12, 0x17, 0x0a, 0x07, 0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x6e, 0x6f, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x6f, 0x6c, 0x65, 0x4e, 0x6f, 0x22, 0x3b, 0x0a, 0x0c,
+	0x52, 0x6f, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x12, 0x17, 0x0a, 0x07,
+	0x72, 0x6f, 0x6c, 0x65, 0x5f, 0x6e, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72,
+	0x6f, 0x6c, 0x65, 0x4e, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x32, 0xe3, 0x02, 0x0a, 0x06, 0x47, 0x6f,
+	0x41, 0x75, 0x74, 0x68, 0x12, 0x49, 0x0a, 0x12, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x2e, 0x67, 0x6f, 0x61,
+	0x75, 0x74, 0x68, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x71, 0x1a, 0x19, 0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x54, 0x65,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12,
+	0x58, 0x0a, 0x17, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1d, 0x2e, 0x67, 0x6f, 0x61,
+	0x75, 0x74, 0x68, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1e, 0x2e, 0x67, 0x6f, 0x61, 0x75,
+	0x74, 0x68, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x41,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x3b, 0x0a, 0x0a, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x61,
+	0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x12, 0x3d, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74,
+	0x68, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x15,
+	0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x38, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x6c, 0x65,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x13, 0x2e, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x52, 0x6f,
+	0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x71, 0x1a, 0x14, 0x2e, 0x67, 0x6f, 0x61, 0x75,
+	0x74, 0x68, 0x2e, 0x52, 0x6f, 0x6c, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x65, 0x73, 0x70, 0x42,
+	0x29, 0x5a, 0x27, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x63, 0x75,
+	0x72, 0x74, 0x69, 0x73, 0x6e, 0x65, 0x77, 0x62, 0x69, 0x65, 0x2f, 0x67, 0x6f, 0x61, 0x75, 0x74,
+	0x68, 0x2f, 0x67, 0x6f, 0x61, 0x75, 0x74, 0x68, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_goauth_proto_rawDescOnce sync.Once
+	file_goauth_proto_rawDescData = file_goauth_proto_rawDesc
+)
+
+func file_goauth_proto_rawDescGZIP() []byte {
+	file_goauth_proto_rawDescOnce.Do(func() {
+		file_goauth_proto_rawDescData = protoimpl.X.CompressGZIP(file_goauth_proto_rawDescData)
+	})
+	return file_goauth_proto_rawDescData
+}
+
+var file_goauth_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_goauth_proto_goTypes = []interface{}{
+	(*TestResAccessReq)(nil),       // 0: goauth.TestResAccessReq
+	(*TestResAccessResp)(nil),      // 1: goauth.TestResAccessResp
+	(*BatchTestResAccessReq)(nil),  // 2: goauth.BatchTestResAccessReq
+	(*ResAccessTestItem)(nil),      // 3: goauth.ResAccessTestItem
+	(*BatchTestResAccessResp)(nil), // 4: goauth.BatchTestResAccessResp
+	(*ResAccessTestResult)(nil),    // 5: goauth.ResAccessTestResult
+	(*CreatePathReq)(nil),          // 6: goauth.CreatePathReq
+	(*CreatePathResp)(nil),         // 7: goauth.CreatePathResp
+	(*CreateResReq)(nil),           // 8: goauth.CreateResReq
+	(*CreateResResp)(nil),          // 9: goauth.CreateResResp
+	(*RoleInfoReq)(nil),            // 10: goauth.RoleInfoReq
+	(*RoleInfoResp)(nil),           // 11: goauth.RoleInfoResp
+}
+var file_goauth_proto_depIdxs = []int32{
+	3,  // 0: goauth.BatchTestResAccessReq.items:type_name -> goauth.ResAccessTestItem
+	5,  // 1: goauth.BatchTestResAccessResp.items:type_name -> goauth.ResAccessTestResult
+	0,  // 2: goauth.GoAuth.TestResourceAccess:input_type -> goauth.TestResAccessReq
+	2,  // 3: goauth.GoAuth.BatchTestResourceAccess:input_type -> goauth.BatchTestResAccessReq
+	6,  // 4: goauth.GoAuth.CreatePath:input_type -> goauth.CreatePathReq
+	8,  // 5: goauth.GoAuth.CreateResource:input_type -> goauth.CreateResReq
+	10, // 6: goauth.GoAuth.GetRoleInfo:input_type -> goauth.RoleInfoReq
+	1,  // 7: goauth.GoAuth.TestResourceAccess:output_type -> goauth.TestResAccessResp
+	4,  // 8: goauth.GoAuth.BatchTestResourceAccess:output_type -> goauth.BatchTestResAccessResp
+	7,  // 9: goauth.GoAuth.CreatePath:output_type -> goauth.CreatePathResp
+	9,  // 10: goauth.GoAuth.CreateResource:output_type -> goauth.CreateResResp
+	11, // 11: goauth.GoAuth.GetRoleInfo:output_type -> goauth.RoleInfoResp
+	7,  // [7:12] is the sub-list for method output_type
+	2,  // [2:7] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_goauth_proto_init() }
+func file_goauth_proto_init() {
+	if File_goauth_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_goauth_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestResAccessReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestResAccessResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchTestResAccessReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResAccessTestItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchTestResAccessResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResAccessTestResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePathReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreatePathResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateResReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateResResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoleInfoReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_goauth_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RoleInfoResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_goauth_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_goauth_proto_goTypes,
+		DependencyIndexes: file_goauth_proto_depIdxs,
+		MessageInfos:      file_goauth_proto_msgTypes,
+	}.Build()
+	File_goauth_proto = out.File
+	file_goauth_proto_rawDesc = nil
+	file_goauth_proto_goTypes = nil
+	file_goauth_proto_depIdxs = nil
+}
@@ -0,0 +1,267 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: goauth.proto
+
+package goauthpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	GoAuth_TestResourceAccess_FullMethodName      = "/goauth.GoAuth/TestResourceAccess"
+	GoAuth_BatchTestResourceAccess_FullMethodName = "/goauth.GoAuth/BatchTestResourceAccess"
+	GoAuth_CreatePath_FullMethodName              = "/goauth.GoAuth/CreatePath"
+	GoAuth_CreateResource_FullMethodName          = "/goauth.GoAuth/CreateResource"
+	GoAuth_GetRoleInfo_FullMethodName             = "/goauth.GoAuth/GetRoleInfo"
+)
+
+// GoAuthClient is the client API for GoAuth service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type GoAuthClient interface {
+	// Test access to resource, same as '/remote/path/resource/access-test'
+	TestResourceAccess(ctx context.Context, in *TestResAccessReq, opts ...grpc.CallOption) (*TestResAccessResp, error)
+	// Test access to resources in batch, same as '/remote/path/resource/access-test/batch'
+	BatchTestResourceAccess(ctx context.Context, in *BatchTestResAccessReq, opts ...grpc.CallOption) (*BatchTestResAccessResp, error)
+	// Create path if not exist, same as '/remote/path/add'
+	CreatePath(ctx context.Context, in *CreatePathReq, opts ...grpc.CallOption) (*CreatePathResp, error)
+	// Create resource if not exist, same as '/remote/resource/add'
+	CreateResource(ctx context.Context, in *CreateResReq, opts ...grpc.CallOption) (*CreateResResp, error)
+	// Get role info, same as '/remote/role/info'
+	GetRoleInfo(ctx context.Context, in *RoleInfoReq, opts ...grpc.CallOption) (*RoleInfoResp, error)
+}
+
+type goAuthClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoAuthClient(cc grpc.ClientConnInterface) GoAuthClient {
+	return &goAuthClient{cc}
+}
+
+func (c *goAuthClient) TestResourceAccess(ctx context.Context, in *TestResAccessReq, opts ...grpc.CallOption) (*TestResAccessResp, error) {
+	out := new(TestResAccessResp)
+	err := c.cc.Invoke(ctx, GoAuth_TestResourceAccess_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goAuthClient) BatchTestResourceAccess(ctx context.Context, in *BatchTestResAccessReq, opts ...grpc.CallOption) (*BatchTestResAccessResp, error) {
+	out := new(BatchTestResAccessResp)
+	err := c.cc.Invoke(ctx, GoAuth_BatchTestResourceAccess_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goAuthClient) CreatePath(ctx context.Context, in *CreatePathReq, opts ...grpc.CallOption) (*CreatePathResp, error) {
+	out := new(CreatePathResp)
+	err := c.cc.Invoke(ctx, GoAuth_CreatePath_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goAuthClient) CreateResource(ctx context.Context, in *CreateResReq, opts ...grpc.CallOption) (*CreateResResp, error) {
+	out := new(CreateResResp)
+	err := c.cc.Invoke(ctx, GoAuth_CreateResource_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goAuthClient) GetRoleInfo(ctx context.Context, in *RoleInfoReq, opts ...grpc.CallOption) (*RoleInfoResp, error) {
+	out := new(RoleInfoResp)
+	err := c.cc.Invoke(ctx, GoAuth_GetRoleInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GoAuthServer is the server API for GoAuth service.
+// All implementations must embed UnimplementedGoAuthServer
+// for forward compatibility
+type GoAuthServer interface {
+	// Test access to resource, same as '/remote/path/resource/access-test'
+	TestResourceAccess(context.Context, *TestResAccessReq) (*TestResAccessResp, error)
+	// Test access to resources in batch, same as '/remote/path/resource/access-test/batch'
+	BatchTestResourceAccess(context.Context, *BatchTestResAccessReq) (*BatchTestResAccessResp, error)
+	// Create path if not exist, same as '/remote/path/add'
+	CreatePath(context.Context, *CreatePathReq) (*CreatePathResp, error)
+	// Create resource if not exist, same as '/remote/resource/add'
+	CreateResource(context.Context, *CreateResReq) (*CreateResResp, error)
+	// Get role info, same as '/remote/role/info'
+	GetRoleInfo(context.Context, *RoleInfoReq) (*RoleInfoResp, error)
+	mustEmbedUnimplementedGoAuthServer()
+}
+
+// UnimplementedGoAuthServer must be embedded to have forward compatible implementations.
+type UnimplementedGoAuthServer struct {
+}
+
+func (UnimplementedGoAuthServer) TestResourceAccess(context.Context, *TestResAccessReq) (*TestResAccessResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestResourceAccess not implemented")
+}
+func (UnimplementedGoAuthServer) BatchTestResourceAccess(context.Context, *BatchTestResAccessReq) (*BatchTestResAccessResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchTestResourceAccess not implemented")
+}
+func (UnimplementedGoAuthServer) CreatePath(context.Context, *CreatePathReq) (*CreatePathResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePath not implemented")
+}
+func (UnimplementedGoAuthServer) CreateResource(context.Context, *CreateResReq) (*CreateResResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateResource not implemented")
+}
+func (UnimplementedGoAuthServer) GetRoleInfo(context.Context, *RoleInfoReq) (*RoleInfoResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRoleInfo not implemented")
+}
+func (UnimplementedGoAuthServer) mustEmbedUnimplementedGoAuthServer() {}
+
+// UnsafeGoAuthServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoAuthServer will
+// result in compilation errors.
+type UnsafeGoAuthServer interface {
+	mustEmbedUnimplementedGoAuthServer()
+}
+
+func RegisterGoAuthServer(s grpc.ServiceRegistrar, srv GoAuthServer) {
+	s.RegisterService(&GoAuth_ServiceDesc, srv)
+}
+
+func _GoAuth_TestResourceAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestResAccessReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoAuthServer).TestResourceAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoAuth_TestResourceAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoAuthServer).TestResourceAccess(ctx, req.(*TestResAccessReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoAuth_BatchTestResourceAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchTestResAccessReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoAuthServer).BatchTestResourceAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoAuth_BatchTestResourceAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoAuthServer).BatchTestResourceAccess(ctx, req.(*BatchTestResAccessReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoAuth_CreatePath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePathReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoAuthServer).CreatePath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoAuth_CreatePath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoAuthServer).CreatePath(ctx, req.(*CreatePathReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoAuth_CreateResource_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateResReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoAuthServer).CreateResource(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoAuth_CreateResource_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoAuthServer).CreateResource(ctx, req.(*CreateResReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoAuth_GetRoleInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoleInfoReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoAuthServer).GetRoleInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoAuth_GetRoleInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoAuthServer).GetRoleInfo(ctx, req.(*RoleInfoReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GoAuth_ServiceDesc is the grpc.ServiceDesc for GoAuth service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoAuth_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goauth.GoAuth",
+	HandlerType: (*GoAuthServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TestResourceAccess",
+			Handler:    _GoAuth_TestResourceAccess_Handler,
+		},
+		{
+			MethodName: "BatchTestResourceAccess",
+			Handler:    _GoAuth_BatchTestResourceAccess_Handler,
+		},
+		{
+			MethodName: "CreatePath",
+			Handler:    _GoAuth_CreatePath_Handler,
+		},
+		{
+			MethodName: "CreateResource",
+			Handler:    _GoAuth_CreateResource_Handler,
+		},
+		{
+			MethodName: "GetRoleInfo",
+			Handler:    _GoAuth_GetRoleInfo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "goauth.proto",
+}
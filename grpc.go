@@ -0,0 +1,167 @@
+package goauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/curtisnewbie/goauth/goauthpb"
+	"github.com/curtisnewbie/gocommon/common"
+	"github.com/curtisnewbie/miso/miso"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	PropGrpcEnabled = "goauth.grpc.enabled"
+	PropGrpcPort    = "goauth.grpc.port"
+)
+
+func init() {
+	miso.SetDefProp(PropGrpcEnabled, false)
+	miso.SetDefProp(PropGrpcPort, 8082)
+}
+
+// gRPC equivalent of the endpoints under '/remote'
+type grpcServer struct {
+	goauthpb.UnimplementedGoAuthServer
+}
+
+func (s grpcServer) TestResourceAccess(ctx context.Context, req *goauthpb.TestResAccessReq) (*goauthpb.TestResAccessResp, error) {
+	timer := miso.NewHistTimer(resourceAccessCheckHisto)
+	defer timer.ObserveDuration()
+
+	r := TestResAccessReq{RoleNo: req.RoleNo, RoleNos: req.RoleNos, Url: req.Url, Method: req.Method}
+	if e := miso.Validate(r); e != nil {
+		return nil, status.Error(codes.InvalidArgument, e.Error())
+	}
+	resp, e := TestResourceAccess(miso.NewRail(ctx), r)
+	if e != nil {
+		return nil, toGrpcErr(e)
+	}
+	return &goauthpb.TestResAccessResp{Valid: resp.Valid}, nil
+}
+
+func (s grpcServer) BatchTestResourceAccess(ctx context.Context, req *goauthpb.BatchTestResAccessReq) (*goauthpb.BatchTestResAccessResp, error) {
+	timer := miso.NewHistTimer(batchResourceAccessCheckHisto)
+	defer timer.ObserveDuration()
+
+	r := BatchTestResAccessReq{RoleNo: req.RoleNo, RoleNos: req.RoleNos, Items: make([]ResAccessTestItem, 0, len(req.Items))}
+	for _, it := range req.Items {
+		r.Items = append(r.Items, ResAccessTestItem{Url: it.Url, Method: it.Method})
+	}
+	if e := miso.Validate(r); e != nil {
+		return nil, status.Error(codes.InvalidArgument, e.Error())
+	}
+	resp, e := BatchTestResourceAccess(miso.NewRail(ctx), r)
+	if e != nil {
+		return nil, toGrpcErr(e)
+	}
+
+	items := make([]*goauthpb.ResAccessTestResult, 0, len(resp.Items))
+	for _, it := range resp.Items {
+		items = append(items, &goauthpb.ResAccessTestResult{Url: it.Url, Method: it.Method, Valid: it.Valid})
+	}
+	return &goauthpb.BatchTestResAccessResp{Items: items}, nil
+}
+
+func (s grpcServer) CreatePath(ctx context.Context, req *goauthpb.CreatePathReq) (*goauthpb.CreatePathResp, error) {
+	r := CreatePathReq{
+		Type:    PathType(req.Type),
+		Url:     req.Url,
+		Group:   req.Group,
+		Method:  req.Method,
+		Desc:    req.Desc,
+		ResCode: req.ResCode,
+	}
+	if e := miso.Validate(r); e != nil {
+		return nil, status.Error(codes.InvalidArgument, e.Error())
+	}
+	if e := CreatePathIfNotExist(miso.NewRail(ctx), r, grpcReportingService(ctx), common.NilUser()); e != nil {
+		return nil, toGrpcErr(e)
+	}
+	return &goauthpb.CreatePathResp{}, nil
+}
+
+func (s grpcServer) CreateResource(ctx context.Context, req *goauthpb.CreateResReq) (*goauthpb.CreateResResp, error) {
+	r := CreateResReq{Name: req.Name, Code: req.Code}
+	if e := miso.Validate(r); e != nil {
+		return nil, status.Error(codes.InvalidArgument, e.Error())
+	}
+	if e := CreateResourceIfNotExist(miso.NewRail(ctx), r, grpcReportingService(ctx), common.NilUser()); e != nil {
+		return nil, toGrpcErr(e)
+	}
+	return &goauthpb.CreateResResp{}, nil
+}
+
+// Name of the service that reports resources and paths, it's the value of metadata HeaderReportingService
+func grpcReportingService(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(HeaderReportingService); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (s grpcServer) GetRoleInfo(ctx context.Context, req *goauthpb.RoleInfoReq) (*goauthpb.RoleInfoResp, error) {
+	r := RoleInfoReq{RoleNo: req.RoleNo}
+	if e := miso.Validate(r); e != nil {
+		return nil, status.Error(codes.InvalidArgument, e.Error())
+	}
+	resp, e := GetRoleInfo(miso.NewRail(ctx), r)
+	if e != nil {
+		return nil, toGrpcErr(e)
+	}
+	return &goauthpb.RoleInfoResp{RoleNo: resp.RoleNo, Name: resp.Name}, nil
+}
+
+// Map business errors (*miso.MisoErr) to gRPC status by their error codes, other errors are internal
+func toGrpcErr(e error) error {
+	var me *miso.MisoErr
+	if !errors.As(e, &me) {
+		return status.Error(codes.Internal, e.Error())
+	}
+
+	switch me.Code {
+	case ErrCodeRoleNotFound, ErrCodePathNotFound, ErrCodeResourceNotFound, ErrCodeTrashItemNotFound:
+		return status.Error(codes.NotFound, me.Msg)
+	case ErrCodeIllegalArgument:
+		return status.Error(codes.InvalidArgument, me.Msg)
+	case ErrCodeIllegalState:
+		return status.Error(codes.FailedPrecondition, me.Msg)
+	}
+	return status.Error(codes.Unknown, me.Msg)
+}
+
+// Serve the gRPC service alongside the http server, only when 'goauth.grpc.enabled' is true
+func BootstrapGrpcServer(rail miso.Rail) error {
+	if !miso.GetPropBool(PropGrpcEnabled) {
+		return nil
+	}
+
+	addr := fmt.Sprintf(":%d", miso.GetPropInt(PropGrpcPort))
+	lis, e := net.Listen("tcp", addr)
+	if e != nil {
+		return fmt.Errorf("failed to listen on %v for grpc server, %w", addr, e)
+	}
+
+	server := grpc.NewServer()
+	goauthpb.RegisterGoAuthServer(server, grpcServer{})
+	miso.AddShutdownHook(func() {
+		server.GracefulStop()
+	})
+
+	go func() {
+		if e := server.Serve(lis); e != nil {
+			rail.Errorf("grpc server stopped, %v", e)
+		}
+	}()
+	rail.Infof("grpc server listening on %v", addr)
+	return nil
+}
@@ -0,0 +1,30 @@
+package goauth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/curtisnewbie/miso/miso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToGrpcErr(t *testing.T) {
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{miso.NewErrCode(ErrCodeRoleNotFound, "Role not found"), codes.NotFound},
+		{miso.NewErrCode(ErrCodePathNotFound, "Path not found"), codes.NotFound},
+		{miso.NewErrCode(ErrCodeResourceNotFound, "Resources not found: [a]"), codes.NotFound},
+		{miso.NewErrCode(ErrCodeIllegalArgument, "Invalid resource mode"), codes.InvalidArgument},
+		{miso.NewErrCode(ErrCodeIllegalState, "Path already exists"), codes.FailedPrecondition},
+		{miso.NewErr("Unknown audit sink: x"), codes.Unknown},
+		{errors.New("connection refused"), codes.Internal},
+	}
+	for _, c := range cases {
+		if code := status.Code(toGrpcErr(c.err)); code != c.code {
+			t.Fatalf("%v should be mapped to %v, but got %v", c.err, c.code, code)
+		}
+	}
+}
@@ -77,15 +77,18 @@ func QueryResourcePathAsync(rail miso.Rail, server miso.Server, m MonitoredServi
 			rail.Debugf("service %v (%v:%v), returned resouces/paths: %+v", m.Service, server.Address, server.Port, res)
 			user := common.NilUser() // just to satisfy the method, it's always a zero value
 			for _, r := range res.Resources {
-				if err := CreateResourceIfNotExist(rail, r, user); err != nil {
+				if err := CreateResourceIfNotExist(rail, r, m.Service, user); err != nil {
 					rail.Errorf("failed to create resource, req: %+v, %v", r, err)
 				}
 			}
 			for _, r := range res.Paths {
-				if err := CreatePathIfNotExist(rail, r, user); err != nil {
+				if err := CreatePathIfNotExist(rail, r, m.Service, user); err != nil {
 					rail.Errorf("failed to create path, req: %+v, %v", r, err)
 				}
 			}
+			if err := MarkStalePaths(rail, m.Service, res.Paths); err != nil {
+				rail.Errorf("failed to mark stale paths, service: %v, %v", m.Service, err)
+			}
 		}
 	})
 }
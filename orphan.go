@@ -0,0 +1,229 @@
+package goauth
+
+import (
+	"github.com/curtisnewbie/miso/miso"
+	"gorm.io/gorm"
+)
+
+type OrphanReport struct {
+	OrphanResources []ResBrief        `json:"orphanResources"` // resources bound to no path and granted to no role
+	UnboundPaths    []PathBrief       `json:"unboundPaths"`    // protected paths bound to no resource, these are always rejected
+	EmptyRoles      []RoleBrief       `json:"emptyRoles"`      // roles with no resources granted nor inherited, administrators excluded
+	DanglingRoleRes []DanglingRoleRes `json:"danglingRoleRes"` // role_resource referencing nonexistent resources
+	DanglingPathRes []DanglingPathRes `json:"danglingPathRes"` // path_resource referencing nonexistent resources
+}
+
+type DanglingRoleRes struct {
+	RoleNo  string `json:"roleNo"`
+	ResCode string `json:"resCode"`
+}
+
+type DanglingPathRes struct {
+	PathNo  string `json:"pathNo"`
+	ResCode string `json:"resCode"`
+}
+
+type CleanOrphansReq struct {
+	OrphanResources  bool `json:"orphanResources"`  // delete the orphaned resources
+	DanglingBindings bool `json:"danglingBindings"` // delete role_resource and path_resource referencing nonexistent resources
+}
+
+type CleanOrphansResp struct {
+	DeletedResources []string `json:"deletedResources"` // codes of the resources deleted
+	DeletedRoleRes   int      `json:"deletedRoleRes"`   // number of role_resource deleted
+	DeletedPathRes   int      `json:"deletedPathRes"`   // number of path_resource deleted
+}
+
+// Report orphaned resources, unbound paths, empty roles and bindings that reference nonexistent resources
+func ReportOrphans(ec miso.Rail) (OrphanReport, error) {
+	db := miso.GetMySQL()
+	rep := OrphanReport{}
+
+	res, e := listOrphanResources(db)
+	if e != nil {
+		return rep, e
+	}
+	rep.OrphanResources = res
+
+	var paths []PathBrief
+	tx := db.Raw("select p.path_no, p.method, p.url, p.`desc` from path p "+
+		"where p.ptype = ? and p.is_del = 0 and not exists (select * from path_resource pr where pr.path_no = p.path_no and pr.is_del = 0)", PtProtected).
+		Scan(&paths)
+	if tx.Error != nil {
+		return rep, tx.Error
+	}
+	if paths == nil {
+		paths = []PathBrief{}
+	}
+	rep.UnboundPaths = paths
+
+	var roles []RoleBrief
+	tx = db.Raw(`select ro.role_no, ro.name from role ro
+		where ro.is_del = 0
+		and not exists (select * from role_resource rr where rr.role_no = ro.role_no and rr.is_del = 0)
+		and not exists (select * from role_parent rp where rp.role_no = ro.role_no and rp.is_del = 0)`).
+		Scan(&roles)
+	if tx.Error != nil {
+		return rep, tx.Error
+	}
+	rep.EmptyRoles = []RoleBrief{}
+	for _, r := range roles {
+		if !isAdminRole(r.RoleNo) {
+			rep.EmptyRoles = append(rep.EmptyRoles, r)
+		}
+	}
+
+	roleRes, e := listDanglingRoleRes(db)
+	if e != nil {
+		return rep, e
+	}
+	rep.DanglingRoleRes = roleRes
+
+	pathRes, e := listDanglingPathRes(db)
+	if e != nil {
+		return rep, e
+	}
+	rep.DanglingPathRes = pathRes
+
+	return rep, nil
+}
+
+// Delete orphaned resources and bindings that reference nonexistent resources, paths and roles are never deleted
+func CleanOrphans(ec miso.Rail, req CleanOrphansReq) (CleanOrphansResp, error) {
+	resp := CleanOrphansResp{DeletedResources: []string{}}
+	if !req.OrphanResources && !req.DanglingBindings {
+		return resp, nil
+	}
+
+	var roleRes []DanglingRoleRes
+	var denyRoles []string
+	delNo := genDelNo()
+	_, e := lockResourceGlobal(ec, func() (any, error) {
+		return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+			if req.OrphanResources {
+				res, e := listOrphanResources(tx)
+				if e != nil {
+					return e
+				}
+				for _, r := range res {
+					resp.DeletedResources = append(resp.DeletedResources, r.Code)
+				}
+				if len(resp.DeletedResources) > 0 {
+					codes := resp.DeletedResources
+					if t := tx.Raw(`select distinct role_no from role_deny where res_code in ? and is_del = 0`, codes).Scan(&denyRoles); t.Error != nil {
+						return t.Error
+					}
+					if t := tx.Exec(`update resource set is_del = 1, del_no = ? where code in ? and is_del = 0`, delNo, codes); t.Error != nil {
+						return t.Error
+					}
+					if t := tx.Exec(`update role_deny set is_del = 1, del_no = ? where res_code in ? and is_del = 0`, delNo, codes); t.Error != nil {
+						return t.Error
+					}
+				}
+			}
+
+			if req.DanglingBindings {
+				var e error
+				if roleRes, e = listDanglingRoleRes(tx); e != nil {
+					return e
+				}
+				t := tx.Exec(`update role_resource rr set rr.is_del = 1 where rr.is_del = 0
+					and not exists (select * from resource r where ` + roleResMatchCond + ` and r.is_del = 0)`)
+				if t.Error != nil {
+					return t.Error
+				}
+				resp.DeletedRoleRes = int(t.RowsAffected)
+
+				t = tx.Exec(`update path_resource pr set pr.is_del = 1 where pr.is_del = 0
+					and not exists (select * from resource r where r.code = pr.res_code and r.is_del = 0)`)
+				if t.Error != nil {
+					return t.Error
+				}
+				resp.DeletedPathRes = int(t.RowsAffected)
+			}
+			return nil
+		})
+	})
+	if e != nil {
+		return CleanOrphansResp{}, e
+	}
+
+	for _, code := range resp.DeletedResources {
+		if err := resCodeCache.Del(ec, code); err != nil {
+			ec.Errorf("failed to evict resCodeCache, %v, %v", code, err)
+		}
+	}
+	ec.Infof("Cleaned orphans, deleted resources: %v, role_resource: %d, path_resource: %d",
+		resp.DeletedResources, resp.DeletedRoleRes, resp.DeletedPathRes)
+
+	if resp.DeletedRoleRes > 0 || resp.DeletedPathRes > 0 {
+		publishAllChange(ec)
+
+		// asynchronously reload the cache of paths and resources
+		go func() {
+			if e := LoadPathResCache(ec); e != nil {
+				ec.Errorf("Failed to load path resource cache, %v", e)
+			}
+		}()
+	}
+
+	// evict the removed grants, including the prefix grants, from the cache of the roles and their descendants
+	removed := map[string][]string{}
+	for _, rr := range roleRes {
+		removed[rr.RoleNo] = append(removed[rr.RoleNo], rr.ResCode)
+	}
+	for roleNo, codes := range removed {
+		if e := refreshResOfRoleTree(ec, roleNo, codes); e != nil {
+			return resp, e
+		}
+	}
+	if e := evictDenyOfRoles(ec, denyRoles); e != nil {
+		return resp, e
+	}
+	return resp, nil
+}
+
+// List resources bound to no path and granted to no role, resources covered by prefix grants are considered granted
+func listOrphanResources(db *gorm.DB) ([]ResBrief, error) {
+	var res []ResBrief
+	tx := db.Raw("select r.name, r.code, r.desc, r.category from resource r " +
+		"where r.is_del = 0 " +
+		"and not exists (select * from path_resource pr where pr.res_code = r.code and pr.is_del = 0) " +
+		"and not exists (select * from role_resource rr where " + roleResMatchCond + " and rr.is_del = 0)").
+		Scan(&res)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if res == nil {
+		res = []ResBrief{}
+	}
+	return res, nil
+}
+
+func listDanglingRoleRes(db *gorm.DB) ([]DanglingRoleRes, error) {
+	var l []DanglingRoleRes
+	tx := db.Raw("select rr.role_no, rr.res_code from role_resource rr " +
+		"where rr.is_del = 0 and not exists (select * from resource r where " + roleResMatchCond + " and r.is_del = 0)").
+		Scan(&l)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if l == nil {
+		l = []DanglingRoleRes{}
+	}
+	return l, nil
+}
+
+func listDanglingPathRes(db *gorm.DB) ([]DanglingPathRes, error) {
+	var l []DanglingPathRes
+	tx := db.Raw("select pr.path_no, pr.res_code from path_resource pr " +
+		"where pr.is_del = 0 and not exists (select * from resource r where r.code = pr.res_code and r.is_del = 0)").
+		Scan(&l)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if l == nil {
+		l = []DanglingPathRes{}
+	}
+	return l, nil
+}
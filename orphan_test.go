@@ -0,0 +1,82 @@
+package goauth
+
+import (
+	"testing"
+
+	"github.com/curtisnewbie/gocommon/common"
+	"github.com/curtisnewbie/miso/miso"
+)
+
+func TestReportOrphans(t *testing.T) {
+	before(t)
+
+	rep, e := ReportOrphans(miso.EmptyRail())
+	if e != nil {
+		t.Fatal(e)
+	}
+	if rep.OrphanResources == nil || rep.DanglingRoleRes == nil || rep.DanglingPathRes == nil {
+		t.Fatalf("report should not contain nil slices, %+v", rep)
+	}
+	for _, rr := range rep.DanglingRoleRes {
+		var n int
+		if tx := miso.GetMySQL().Raw(`select count(*) from resource r, role_resource rr
+			where rr.role_no = ? and rr.res_code = ? and `+roleResMatchCond+` and r.is_del = 0`, rr.RoleNo, rr.ResCode).Scan(&n); tx.Error != nil {
+			t.Fatal(tx.Error)
+		}
+		if n > 0 {
+			t.Fatalf("role_resource %+v references live resource", rr)
+		}
+	}
+	t.Logf("%+v", rep)
+}
+
+func TestCleanOrphans(t *testing.T) {
+	before(t)
+	rail := miso.EmptyRail()
+
+	code := miso.GenIdP("test_")
+	if e := CreateResourceIfNotExist(rail, CreateResReq{Name: "Test Orphan", Code: code}, "", common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+
+	roleNo := "role_554107924873216177918"
+	danglingCode := miso.GenIdP("dangling_")
+	if e := miso.GetMySQL().Exec(`insert into role_resource (role_no, res_code) values (?, ?)`, roleNo, danglingCode).Error; e != nil {
+		t.Fatal(e)
+	}
+	if e := roleResCache.Put(rail, roleResCacheKey(roleNo, danglingCode), roleResCacheVal(nil)); e != nil {
+		t.Fatal(e)
+	}
+
+	resp, e := CleanOrphans(rail, CleanOrphansReq{OrphanResources: true, DanglingBindings: true})
+	if e != nil {
+		t.Fatal(e)
+	}
+	t.Logf("%+v", resp)
+
+	if !containsStr(resp.DeletedResources, code) {
+		t.Fatalf("orphan resource %v should be deleted, %+v", code, resp)
+	}
+	if resp.DeletedRoleRes < 1 {
+		t.Fatalf("dangling role_resource should be deleted, %+v", resp)
+	}
+	if ok, e := resCodeCache.Exists(rail, code); e != nil || ok {
+		t.Fatalf("resCodeCache of %v should be evicted, %v", code, e)
+	}
+	if ok, e := roleResCache.Exists(rail, roleResCacheKey(roleNo, danglingCode)); e != nil || ok {
+		t.Fatalf("roleResCache of %v should be evicted, %v", danglingCode, e)
+	}
+
+	rep, e := ReportOrphans(rail)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(rep.DanglingRoleRes) > 0 {
+		t.Fatalf("dangling role_resource should be cleaned, %+v", rep.DanglingRoleRes)
+	}
+	for _, r := range rep.OrphanResources {
+		if r.Code == code {
+			t.Fatalf("orphan resource %v should be cleaned", code)
+		}
+	}
+}
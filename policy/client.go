@@ -0,0 +1,148 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/curtisnewbie/miso/miso"
+)
+
+const (
+	// fanout exchange where goauth publishes ChangeEvent, every Syncer binds its own queue to it
+	ChangeExchange = "goauth.policy.change.fanout"
+
+	CtPath = "PATH" // a path is changed or deleted
+	CtRole = "ROLE" // resources or deny rules of a role are changed, or the role is deleted
+	CtAll  = "ALL"  // anything may be changed, a full snapshot should be loaded
+
+	SnapshotPath = "/remote/policy/snapshot"
+	PathPath     = "/remote/policy/path"
+	RolePath     = "/remote/policy/role"
+)
+
+// Notification of policy change, published by goauth
+type ChangeEvent struct {
+	Type   string `json:"type"`   // PATH, ROLE, ALL
+	PathNo string `json:"pathNo"` // present when Type is PATH
+	RoleNo string `json:"roleNo"` // present when Type is ROLE
+}
+
+type PathResp struct {
+	Found bool `json:"found"` // false if the path is deleted
+	Path  Path `json:"path"`
+}
+
+type RoleResp struct {
+	Found bool `json:"found"` // false if the role is deleted
+	Role  Role `json:"role"`
+}
+
+// Keeps an Evaluator in sync with goauth.
+//
+// A full snapshot is loaded on Refresh, and the changes are applied incrementally on ChangeEvent.
+type Syncer struct {
+	*Evaluator
+	baseUrl string
+}
+
+// Create Syncer, baseUrl is the url of goauth, e.g., 'http://goauth:8081'
+func NewSyncer(baseUrl string) *Syncer {
+	return &Syncer{Evaluator: NewEvaluator(), baseUrl: strings.TrimRight(baseUrl, "/")}
+}
+
+// Load full snapshot from goauth
+func (s *Syncer) Refresh(rail miso.Rail) error {
+	snapshot, e := FetchSnapshot(rail, s.baseUrl)
+	if e != nil {
+		return e
+	}
+	s.Load(snapshot)
+	rail.Debugf("Loaded policy snapshot, paths: %d, roles: %d", len(snapshot.Paths), len(snapshot.Roles))
+	return nil
+}
+
+// Refresh the full snapshot periodically, in case any ChangeEvent is missed
+func (s *Syncer) RefreshPeriodically(interval time.Duration) {
+	miso.NewTickRuner(interval, func() {
+		rail := miso.EmptyRail()
+		if e := s.Refresh(rail); e != nil {
+			rail.Errorf("Failed to refresh policy snapshot, %v", e)
+		}
+	}).Start()
+}
+
+// Subscribe ChangeEvent using a queue of the instance, it should be called before the server is bootstrapped.
+//
+// The exchange is a fanout exchange, each instance must have its own queue, or the instances will compete for the
+// events and each event will only reach one of them. The queue is auto-deleted once the instance is disconnected, the
+// events missed in between are covered by Refresh.
+func (s *Syncer) Subscribe(instance string) {
+	queue := ChangeExchange + "." + instance
+	DeclareChangeExchange()
+	// the queue is only meaningful to this instance, it's deleted once the instance is gone
+	miso.RegisterRabbitQueue(miso.QueueRegistration{Name: queue, Durable: false, AutoDelete: true})
+	miso.RegisterRabbitBinding(miso.BindingRegistration{Queue: queue, RoutingKey: "#", Exchange: ChangeExchange})
+	miso.AddRabbitListener(miso.JsonMsgListener[ChangeEvent]{QueueName: queue, Handler: s.OnChange, NumOfRoutines: 1})
+}
+
+// Declare the fanout exchange of ChangeEvent
+func DeclareChangeExchange() {
+	miso.RegisterRabbitExchange(miso.ExchangeRegistration{Name: ChangeExchange, Kind: "fanout", Durable: true})
+}
+
+// Apply the change incrementally, it's the listener registered by Subscribe
+func (s *Syncer) OnChange(rail miso.Rail, ev ChangeEvent) error {
+	switch ev.Type {
+	case CtPath:
+		r, e := FetchPath(rail, s.baseUrl, ev.PathNo)
+		if e != nil {
+			return e
+		}
+		if r.Found {
+			s.PutPath(r.Path)
+		} else {
+			s.RemovePath(ev.PathNo)
+		}
+	case CtRole:
+		r, e := FetchRole(rail, s.baseUrl, ev.RoleNo)
+		if e != nil {
+			return e
+		}
+		if r.Found {
+			s.PutRole(r.Role)
+		} else {
+			s.RemoveRole(ev.RoleNo)
+		}
+	default:
+		return s.Refresh(rail)
+	}
+	return nil
+}
+
+func FetchSnapshot(rail miso.Rail, baseUrl string) (Snapshot, error) {
+	var resp miso.GnResp[Snapshot]
+	if err := miso.NewTClient(rail, baseUrl+SnapshotPath).Require2xx().Get().Json(&resp); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to fetch policy snapshot, %w", err)
+	}
+	return resp.Res()
+}
+
+func FetchPath(rail miso.Rail, baseUrl string, pathNo string) (PathResp, error) {
+	var resp miso.GnResp[PathResp]
+	q := url.Values{"pathNo": []string{pathNo}}
+	if err := miso.NewTClient(rail, baseUrl+PathPath+"?"+q.Encode()).Require2xx().Get().Json(&resp); err != nil {
+		return PathResp{}, fmt.Errorf("failed to fetch policy path, pathNo: %v, %w", pathNo, err)
+	}
+	return resp.Res()
+}
+
+func FetchRole(rail miso.Rail, baseUrl string, roleNo string) (RoleResp, error) {
+	var resp miso.GnResp[RoleResp]
+	q := url.Values{"roleNo": []string{roleNo}}
+	if err := miso.NewTClient(rail, baseUrl+RolePath+"?"+q.Encode()).Require2xx().Get().Json(&resp); err != nil {
+		return RoleResp{}, fmt.Errorf("failed to fetch policy role, roleNo: %v, %w", roleNo, err)
+	}
+	return resp.Res()
+}
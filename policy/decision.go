@@ -0,0 +1,331 @@
+package policy
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type PathType string
+
+// How the resources bound to a path are required
+type PathResMode string
+
+const (
+	PtProtected PathType = "PROTECTED"
+	PtPublic    PathType = "PUBLIC"
+
+	PrmAny PathResMode = "ANY" // any of the resources is required
+	PrmAll PathResMode = "ALL" // all of the resources are required
+
+	// suffix of the prefix grant, e.g., 'vfm.file.*' grants all the resources with code prefixed by 'vfm.file.'
+	ResCodeWildcardSuffix = ".*"
+
+	// separator of the segments in the hierarchical resource codes, e.g., 'vfm.file.read'
+	ResCodeSeparator = "."
+)
+
+type Path struct {
+	PathNo   string      `json:"pathNo"`
+	Url      string      `json:"url"` // url or url pattern, preprocessed by PreprocessUrl
+	Method   string      `json:"method"`
+	Ptype    PathType    `json:"ptype"`
+	ResCodes []string    `json:"resCodes"` // resources required
+	ResMode  PathResMode `json:"resMode"`
+}
+
+type Role struct {
+	RoleNo       string               `json:"roleNo"`
+	ResCodes     []string             `json:"resCodes"`     // resources accessible, including the ones inherited and the prefix grants
+	ResExpiresAt map[string]time.Time `json:"resExpiresAt"` // when the grants in ResCodes expire, the ones that never expire are absent
+	DenyResCodes []string             `json:"denyResCodes"` // resources denied, including the ones inherited
+	DenyPathNos  []string             `json:"denyPathNos"`  // paths denied, including the ones inherited
+}
+
+// Snapshot of all the paths and roles, it's everything needed to make access decisions
+type Snapshot struct {
+	Paths        []Path   `json:"paths"`
+	Roles        []Role   `json:"roles"`
+	AdminRoleNos []string `json:"adminRoleNos"` // roles that have access to all resources
+}
+
+type AccessReq struct {
+	RoleNo  string   // role no, multiple role nos can be joined with ','
+	RoleNos []string // role nos, merged with RoleNo
+	Url     string
+	Method  string
+}
+
+// Resolve role nos, roleNo may contain multiple role nos joined with ','
+func ResolveRoleNos(roleNo string, roleNos []string) []string {
+	resolved := []string{}
+	seen := map[string]struct{}{}
+	for _, r := range append(strings.Split(roleNo, ","), roleNos...) {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		resolved = append(resolved, r)
+	}
+	return resolved
+}
+
+// Check whether the code is a prefix grant, e.g., 'vfm.file.*'
+func IsResCodePrefixGrant(resCode string) bool {
+	return len(resCode) > len(ResCodeWildcardSuffix) && strings.HasSuffix(resCode, ResCodeWildcardSuffix)
+}
+
+// Prefix grants that cover the resource, from the nearest to the farthest, e.g., 'vfm.file.*' and 'vfm.*' for 'vfm.file.read'
+func ResCodePrefixGrants(resCode string) []string {
+	grants := []string{}
+	for i := strings.LastIndex(resCode, ResCodeSeparator); i > 0; i = strings.LastIndex(resCode[:i], ResCodeSeparator) {
+		grants = append(grants, resCode[:i]+ResCodeWildcardSuffix)
+	}
+	return grants
+}
+
+// Filter out the denied resources, returns false if the remaining resources are no longer sufficient
+func FilterDeniedRes(resCodes []string, mode PathResMode, denied func(resCode string) bool) ([]string, bool) {
+	filtered := []string{}
+	for _, resCode := range resCodes {
+		if denied(resCode) {
+			if mode == PrmAll {
+				return filtered, false
+			}
+			continue
+		}
+		filtered = append(filtered, resCode)
+	}
+	return filtered, len(filtered) > 0
+}
+
+// Check whether the resources are accessible, with PrmAll, all of the resources are required, otherwise any of them is sufficient
+func CheckResMode(resCodes []string, mode PathResMode, granted func(resCode string) (bool, error)) (bool, error) {
+	for _, resCode := range resCodes {
+		ok, e := granted(resCode)
+		if e != nil {
+			return false, e
+		}
+		if mode == PrmAll {
+			if !ok {
+				return false, nil
+			}
+		} else if ok {
+			return true, nil
+		}
+	}
+	return mode == PrmAll, nil
+}
+
+type evalRole struct {
+	resCodes     map[string]struct{}
+	resExpiresAt map[string]time.Time
+	denyResCodes map[string]struct{}
+	denyPathNos  map[string]struct{}
+}
+
+// In-process evaluator of access decisions, the decisions are the same as the ones made by goauth.
+//
+// Evaluator is safe for concurrent use.
+type Evaluator struct {
+	mu       sync.RWMutex
+	paths    map[string]Path      // method:url -> path
+	pathKeys map[string]string    // pathNo -> method:url
+	patterns map[string]*PathTrie // method -> trie of url patterns
+	roles    map[string]evalRole  // roleNo -> role
+	admins   map[string]struct{}  // admin roleNos
+}
+
+func NewEvaluator() *Evaluator {
+	e := &Evaluator{}
+	e.Load(Snapshot{})
+	return e
+}
+
+// Replace everything with the snapshot
+func (e *Evaluator) Load(s Snapshot) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.paths = map[string]Path{}
+	e.pathKeys = map[string]string{}
+	e.patterns = map[string]*PathTrie{}
+	e.roles = map[string]evalRole{}
+	e.admins = toSet(s.AdminRoleNos)
+
+	for _, p := range s.Paths {
+		e.putPath(p)
+	}
+	for _, r := range s.Roles {
+		e.roles[r.RoleNo] = toEvalRole(r)
+	}
+}
+
+// Add or replace path
+func (e *Evaluator) PutPath(p Path) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removePath(p.PathNo)
+	e.putPath(p)
+}
+
+func (e *Evaluator) RemovePath(pathNo string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removePath(pathNo)
+}
+
+// Add or replace role
+func (e *Evaluator) PutRole(r Role) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.roles[r.RoleNo] = toEvalRole(r)
+}
+
+func (e *Evaluator) RemoveRole(roleNo string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.roles, roleNo)
+}
+
+func (e *Evaluator) putPath(p Path) {
+	p.Url = PreprocessUrl(p.Url)
+	p.Method = strings.ToUpper(strings.TrimSpace(p.Method))
+	k := p.Method + ":" + p.Url
+	e.paths[k] = p
+	e.pathKeys[p.PathNo] = k
+
+	if IsUrlPattern(p.Url) {
+		t, ok := e.patterns[p.Method]
+		if !ok {
+			t = NewPathTrie(nil)
+			e.patterns[p.Method] = t
+		}
+		t.Add(p.Url)
+	}
+}
+
+func (e *Evaluator) removePath(pathNo string) {
+	k, ok := e.pathKeys[pathNo]
+	if !ok {
+		return
+	}
+	p := e.paths[k]
+	delete(e.pathKeys, pathNo)
+	delete(e.paths, k)
+
+	// rebuild the trie without the pattern
+	if IsUrlPattern(p.Url) {
+		patterns := []string{}
+		for _, op := range e.paths {
+			if op.Method == p.Method && IsUrlPattern(op.Url) {
+				patterns = append(patterns, op.Url)
+			}
+		}
+		e.patterns[p.Method] = NewPathTrie(patterns)
+	}
+}
+
+func (e *Evaluator) lookupPath(url string, method string) (Path, bool) {
+	if p, ok := e.paths[method+":"+url]; ok {
+		return p, true
+	}
+	t, ok := e.patterns[method]
+	if !ok {
+		return Path{}, false
+	}
+	pattern, ok := t.Match(url)
+	if !ok {
+		return Path{}, false
+	}
+	p, ok := e.paths[method+":"+pattern]
+	return p, ok
+}
+
+// Test access to resource, access is granted if any of the roles has access to the resources required
+func (e *Evaluator) TestAccess(req AccessReq) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	roleNos := ResolveRoleNos(req.RoleNo, req.RoleNos)
+	url := PreprocessUrl(req.Url)
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+
+	p, ok := e.lookupPath(url, method)
+	if !ok {
+		return false
+	}
+	if p.Ptype == PtPublic {
+		return true
+	}
+	if len(roleNos) < 1 || len(p.ResCodes) < 1 {
+		return false
+	}
+
+	// deny rules are evaluated before the grants
+	for _, roleNo := range roleNos {
+		if _, denied := e.roles[roleNo].denyPathNos[p.PathNo]; denied {
+			return false
+		}
+	}
+	resCodes, ok := FilterDeniedRes(p.ResCodes, p.ResMode, func(resCode string) bool {
+		for _, roleNo := range roleNos {
+			if _, denied := e.roles[roleNo].denyResCodes[resCode]; denied {
+				return true
+			}
+		}
+		return false
+	})
+	if !ok {
+		return false
+	}
+
+	now := time.Now()
+	ok, _ = CheckResMode(resCodes, p.ResMode, func(resCode string) (bool, error) {
+		for _, roleNo := range roleNos {
+			if _, isAdmin := e.admins[roleNo]; isAdmin {
+				return true, nil
+			}
+			if e.roles[roleNo].granted(resCode, now) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return ok
+}
+
+// Check whether the resource is granted, either directly or by prefix grants, expired grants are ignored
+func (r evalRole) granted(resCode string, now time.Time) bool {
+	for _, code := range append([]string{resCode}, ResCodePrefixGrants(resCode)...) {
+		if _, ok := r.resCodes[code]; !ok {
+			continue
+		}
+		if exp, ok := r.resExpiresAt[code]; ok && !now.Before(exp) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func toEvalRole(r Role) evalRole {
+	return evalRole{
+		resCodes:     toSet(r.ResCodes),
+		resExpiresAt: r.ResExpiresAt,
+		denyResCodes: toSet(r.DenyResCodes),
+		denyPathNos:  toSet(r.DenyPathNos),
+	}
+}
+
+func toSet(l []string) map[string]struct{} {
+	s := make(map[string]struct{}, len(l))
+	for _, v := range l {
+		s[v] = struct{}{}
+	}
+	return s
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveRoleNos(t *testing.T) {
+	roleNos := ResolveRoleNos(" role_1, role_2,,role_1 ", []string{"role_3", "role_2", " "})
+	if len(roleNos) != 3 || roleNos[0] != "role_1" || roleNos[1] != "role_2" || roleNos[2] != "role_3" {
+		t.Fatalf("incorrect roleNos, %v", roleNos)
+	}
+
+	if roleNos := ResolveRoleNos("", nil); len(roleNos) != 0 {
+		t.Fatalf("should be empty, %v", roleNos)
+	}
+}
+
+func TestEvaluatorTestAccess(t *testing.T) {
+	e := NewEvaluator()
+	e.Load(Snapshot{
+		Paths: []Path{
+			{PathNo: "path_public", Url: "/vfm/open/api/public", Method: "GET", Ptype: PtPublic},
+			{PathNo: "path_file", Url: "/vfm/open/api/file/{fileId}", Method: "GET", Ptype: PtProtected, ResCodes: []string{"file-read"}, ResMode: PrmAny},
+			{PathNo: "path_file_info", Url: "/vfm/open/api/file/info", Method: "GET", Ptype: PtProtected},
+			{PathNo: "path_export", Url: "/vfm/open/api/export", Method: "post", Ptype: PtProtected, ResCodes: []string{"file-read", "export-data"}, ResMode: PrmAll},
+			{PathNo: "path_dir", Url: "/vfm/open/api/dir", Method: "GET", Ptype: PtProtected, ResCodes: []string{"vfm.dir.read"}},
+		},
+		Roles: []Role{
+			{RoleNo: "role_viewer", ResCodes: []string{"file-read"}},
+			{RoleNo: "role_exporter", ResCodes: []string{"export-data"}},
+			{RoleNo: "role_contractor", ResCodes: []string{"file-read", "export-data"}, DenyPathNos: []string{"path_export"}},
+			{RoleNo: "role_vfm", ResCodes: []string{"vfm.*"}},
+		},
+		AdminRoleNos: []string{"role_admin"},
+	})
+
+	cases := []struct {
+		req   AccessReq
+		valid bool
+	}{
+		{AccessReq{Url: "/vfm/open/api/public?abc=1", Method: "GET"}, true},
+		{AccessReq{Url: "/vfm/open/api/unknown", Method: "GET", RoleNo: "role_admin"}, false},
+		{AccessReq{Url: "/vfm/open/api/file/123", Method: "GET"}, false},
+		{AccessReq{Url: "/vfm/open/api/file/123", Method: "get", RoleNo: "role_viewer"}, true},
+		{AccessReq{Url: "/vfm/open/api/file/info", Method: "GET", RoleNo: "role_admin"}, false},
+		{AccessReq{Url: "/vfm/open/api/export", Method: "POST", RoleNo: "role_viewer"}, false},
+		{AccessReq{Url: "/vfm/open/api/export", Method: "POST", RoleNo: "role_viewer,role_exporter"}, true},
+		{AccessReq{Url: "/vfm/open/api/export", Method: "POST", RoleNos: []string{"role_contractor"}}, false},
+		{AccessReq{Url: "/vfm/open/api/export", Method: "POST", RoleNos: []string{"role_admin"}}, true},
+		{AccessReq{Url: "/vfm/open/api/dir", Method: "GET", RoleNo: "role_vfm"}, true},
+		{AccessReq{Url: "/vfm/open/api/dir", Method: "GET", RoleNo: "role_viewer"}, false},
+	}
+	for i, c := range cases {
+		if v := e.TestAccess(c.req); v != c.valid {
+			t.Fatalf("case %d: %+v should be %v, but got %v", i, c.req, c.valid, v)
+		}
+	}
+
+	e.RemovePath("path_file")
+	if e.TestAccess(AccessReq{Url: "/vfm/open/api/file/123", Method: "GET", RoleNo: "role_viewer"}) {
+		t.Fatal("path_file is removed")
+	}
+
+	e.PutRole(Role{RoleNo: "role_viewer", DenyResCodes: []string{"file-read"}})
+	e.PutPath(Path{PathNo: "path_file", Url: "/vfm/open/api/file/**", Method: "GET", Ptype: PtProtected, ResCodes: []string{"file-read"}})
+	if e.TestAccess(AccessReq{Url: "/vfm/open/api/file/123", Method: "GET", RoleNo: "role_viewer"}) {
+		t.Fatal("file-read is denied for role_viewer")
+	}
+}
+
+func TestEvaluatorGrantExpiry(t *testing.T) {
+	e := NewEvaluator()
+	e.Load(Snapshot{
+		Paths: []Path{
+			{PathNo: "path_file", Url: "/vfm/open/api/file", Method: "GET", Ptype: PtProtected, ResCodes: []string{"vfm.file.read"}},
+		},
+		Roles: []Role{
+			{RoleNo: "role_expired", ResCodes: []string{"vfm.file.read"}, ResExpiresAt: map[string]time.Time{"vfm.file.read": time.Now().Add(-time.Minute)}},
+			{RoleNo: "role_temp", ResCodes: []string{"vfm.*"}, ResExpiresAt: map[string]time.Time{"vfm.*": time.Now().Add(time.Hour)}},
+			{RoleNo: "role_mixed", ResCodes: []string{"vfm.file.read", "vfm.*"}, ResExpiresAt: map[string]time.Time{"vfm.file.read": time.Now().Add(-time.Minute)}},
+		},
+	})
+
+	req := AccessReq{Url: "/vfm/open/api/file", Method: "GET"}
+	req.RoleNo = "role_expired"
+	if e.TestAccess(req) {
+		t.Fatal("grant of role_expired is expired")
+	}
+	req.RoleNo = "role_temp"
+	if !e.TestAccess(req) {
+		t.Fatal("grant of role_temp is not expired yet")
+	}
+	req.RoleNo = "role_mixed"
+	if !e.TestAccess(req) {
+		t.Fatal("prefix grant of role_mixed never expires")
+	}
+}
+
+func TestResCodePrefixGrants(t *testing.T) {
+	g := ResCodePrefixGrants("vfm.file.read")
+	if len(g) != 2 || g[0] != "vfm.file.*" || g[1] != "vfm.*" {
+		t.Fatalf("grants: %v", g)
+	}
+	if g := ResCodePrefixGrants("manage-resources"); len(g) != 0 {
+		t.Fatalf("grants: %v", g)
+	}
+	if g := ResCodePrefixGrants(".abc"); len(g) != 0 {
+		t.Fatalf("grants: %v", g)
+	}
+	if !IsResCodePrefixGrant("vfm.*") || IsResCodePrefixGrant(".*") || IsResCodePrefixGrant("vfm.file") {
+		t.Fatal("IsResCodePrefixGrant")
+	}
+}
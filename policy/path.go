@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"strings"
+)
+
+const (
+	// matches exactly one path segment, e.g., '/file/*'
+	segWildcard = "*"
+
+	// matches zero or more path segments, e.g., '/file/**'
+	segMultiWildcard = "**"
+)
+
+// node of PathTrie
+type pathNode struct {
+	static        map[string]*pathNode // static segments
+	variable      *pathNode            // '{var}' segment
+	wildcard      *pathNode            // '*' segment
+	multiWildcard *pathNode            // '**' segment
+	pattern       string               // the pattern that ends at this node
+}
+
+// Trie of url patterns, a url is matched against the most specific pattern.
+//
+// For each segment, static segment is preferred over '{var}', '{var}' is preferred over '*', and '*' is preferred over '**'.
+type PathTrie struct {
+	root *pathNode
+}
+
+func newPathNode() *pathNode {
+	return &pathNode{static: map[string]*pathNode{}}
+}
+
+func NewPathTrie(patterns []string) *PathTrie {
+	t := &PathTrie{root: newPathNode()}
+	for _, p := range patterns {
+		t.Add(p)
+	}
+	return t
+}
+
+// Add pattern to the trie, the pattern should be preprocessed by PreprocessUrl
+func (t *PathTrie) Add(pattern string) {
+	n := t.root
+	for _, seg := range splitUrlSegments(pattern) {
+		var next **pathNode
+		switch {
+		case seg == segMultiWildcard:
+			next = &n.multiWildcard
+		case seg == segWildcard:
+			next = &n.wildcard
+		case isVarSegment(seg):
+			next = &n.variable
+		default:
+			sn, ok := n.static[seg]
+			if !ok {
+				sn = newPathNode()
+				n.static[seg] = sn
+			}
+			n = sn
+			continue
+		}
+		if *next == nil {
+			*next = newPathNode()
+		}
+		n = *next
+	}
+	n.pattern = pattern
+}
+
+// Match url against the patterns, the url should be preprocessed by PreprocessUrl
+func (t *PathTrie) Match(url string) (string, bool) {
+	return t.root.match(splitUrlSegments(url))
+}
+
+func (n *pathNode) match(segs []string) (string, bool) {
+	if len(segs) < 1 {
+		if n.pattern != "" {
+			return n.pattern, true
+		}
+		// '**' also matches zero segment
+		if n.multiWildcard != nil {
+			return n.multiWildcard.match(segs)
+		}
+		return "", false
+	}
+
+	if sn, ok := n.static[segs[0]]; ok {
+		if p, ok := sn.match(segs[1:]); ok {
+			return p, true
+		}
+	}
+	if n.variable != nil {
+		if p, ok := n.variable.match(segs[1:]); ok {
+			return p, true
+		}
+	}
+	if n.wildcard != nil {
+		if p, ok := n.wildcard.match(segs[1:]); ok {
+			return p, true
+		}
+	}
+	if n.multiWildcard != nil {
+		// '**' consumes as few segments as possible
+		for i := 0; i <= len(segs); i++ {
+			if p, ok := n.multiWildcard.match(segs[i:]); ok {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+func splitUrlSegments(url string) []string {
+	url = strings.Trim(url, "/")
+	if url == "" {
+		return []string{}
+	}
+	return strings.Split(url, "/")
+}
+
+func isVarSegment(seg string) bool {
+	return len(seg) > 2 && strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+// Check whether the url is a pattern that contains '{var}', '*' or '**' segments
+func IsUrlPattern(url string) bool {
+	for _, seg := range splitUrlSegments(url) {
+		if seg == segWildcard || seg == segMultiWildcard || isVarSegment(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+// Preprocess url, the processed url will always starts with '/' and never ends with '/'
+func PreprocessUrl(url string) string {
+	ru := []rune(strings.TrimSpace(url))
+	l := len(ru)
+	if l < 1 {
+		return "/"
+	}
+
+	j := strings.LastIndex(url, "?")
+	if j > -1 {
+		ru = ru[0:j]
+		l = len(ru)
+	}
+
+	// never ends with '/'
+	if ru[l-1] == '/' && l > 1 {
+		lj := l - 1
+		for lj > 1 && ru[lj-1] == '/' {
+			lj -= 1
+		}
+
+		ru = ru[0:lj]
+	}
+
+	// always start with '/'
+	if ru[0] != '/' {
+		return "/" + string(ru)
+	}
+	return string(ru)
+}
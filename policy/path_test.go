@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"testing"
+)
+
+func TestPathTrieMatch(t *testing.T) {
+	trie := NewPathTrie([]string{
+		"/vfm/file/{fileId}",
+		"/vfm/file/info",
+		"/vfm/file/*/preview",
+		"/vfm/dir/**",
+		"/vfm/dir/{dirId}/list",
+		"/vfm/**/download",
+	})
+
+	cases := map[string]string{
+		"/vfm/file/123":             "/vfm/file/{fileId}",
+		"/vfm/file/info":            "/vfm/file/info",
+		"/vfm/file/123/preview":     "/vfm/file/*/preview",
+		"/vfm/dir":                  "/vfm/dir/**",
+		"/vfm/dir/1/2/3":            "/vfm/dir/**",
+		"/vfm/dir/1/list":           "/vfm/dir/{dirId}/list",
+		"/vfm/a/b/download":         "/vfm/**/download",
+		"/vfm/download":             "/vfm/**/download",
+		"/vfm/dir/1/download":       "/vfm/dir/**",
+		"/vfm/file/123/preview/abc": "",
+		"/vfm/file":                 "",
+		"/":                         "",
+	}
+
+	for url, expected := range cases {
+		p, ok := trie.Match(url)
+		if expected == "" {
+			if ok {
+				t.Fatalf("'%s' should not match any pattern, but matched '%s'", url, p)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatalf("'%s' should match '%s', but nothing matched", url, expected)
+		}
+		if p != expected {
+			t.Fatalf("'%s' should match '%s', but matched '%s'", url, expected, p)
+		}
+	}
+}
+
+func TestIsUrlPattern(t *testing.T) {
+	if IsUrlPattern("/vfm/file/info") {
+		t.Fatal("/vfm/file/info is not a pattern")
+	}
+	if IsUrlPattern("/vfm/file/{}") {
+		t.Fatal("/vfm/file/{} is not a pattern")
+	}
+	if !IsUrlPattern("/vfm/file/{fileId}") {
+		t.Fatal("/vfm/file/{fileId} is a pattern")
+	}
+	if !IsUrlPattern("/vfm/file/*") {
+		t.Fatal("/vfm/file/* is a pattern")
+	}
+	if !IsUrlPattern("/vfm/**") {
+		t.Fatal("/vfm/** is a pattern")
+	}
+}
+
+func TestPreprocessUrl(t *testing.T) {
+	if v := PreprocessUrl(""); v != "/" {
+		t.Fatal(v)
+	}
+
+	if v := PreprocessUrl("/"); v != "/" {
+		t.Fatal(v)
+	}
+
+	if v := PreprocessUrl("///"); v != "/" {
+		t.Fatal(v)
+	}
+
+	if v := PreprocessUrl("/goauth/test/path"); v != "/goauth/test/path" {
+		t.Fatal(v)
+	}
+
+	if v := PreprocessUrl("/goauth/test/path//"); v != "/goauth/test/path" {
+		t.Fatal(v)
+	}
+
+	if v := PreprocessUrl("goauth/test/path//"); v != "/goauth/test/path" {
+		t.Fatal(v)
+	}
+
+	if v := PreprocessUrl("goauth/test/path?abc=123"); v != "/goauth/test/path" {
+		t.Fatal(v)
+	}
+}
@@ -5,9 +5,13 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/curtisnewbie/goauth/policy"
 	"github.com/curtisnewbie/gocommon/common"
 	"github.com/curtisnewbie/miso/miso"
 	"gorm.io/gorm"
@@ -20,7 +24,10 @@ var (
 	roleInfoCache = miso.NewRCache[RoleInfoResp]("goauth:role:info", miso.RCacheConfig{Exp: 10 * time.Minute, NoSync: true})
 
 	// cache for url's resource, url -> CachedUrlRes
-	urlResCache = miso.NewRCache[CachedUrlRes]("goauth:url:res:v2", miso.RCacheConfig{Exp: 30 * time.Minute})
+	urlResCache = miso.NewRCache[CachedUrlRes]("goauth:url:res:v3", miso.RCacheConfig{Exp: 30 * time.Minute})
+
+	// cache for url patterns of each http method, method -> url patterns
+	urlPatternCache = miso.NewRCache[[]string]("goauth:url:pattern", miso.RCacheConfig{Exp: 30 * time.Minute})
 
 	// pathNo cache
 	pathNoCache = miso.NewRCache[string]("goauth:pathno:cache", miso.RCacheConfig{Exp: 30 * time.Minute, NoSync: true})
@@ -28,18 +35,30 @@ var (
 	// cache for role's resource, role + res -> flag ("1")
 	roleResCache = miso.NewRCache[string]("goauth:role:res", miso.RCacheConfig{Exp: 1 * time.Hour, NoSync: true})
 
+	// cache for role's deny rules, including the ones inherited, role -> CachedRoleDeny
+	roleDenyCache = miso.NewRCache[CachedRoleDeny]("goauth:role:deny", miso.RCacheConfig{Exp: 1 * time.Hour})
+
 	// resourceCode cache
 	resCodeCache = miso.NewRCache[string]("goauth:rescode:cache", miso.RCacheConfig{Exp: 30 * time.Minute, NoSync: true})
 )
 
-type PathType string
+type PathType = policy.PathType
+
+// How the resources bound to a path are required
+type PathResMode = policy.PathResMode
 
 const (
-	// default roleno for admin
+	// default roleno for admin, used when 'goauth.admin.role-nos' is not configured
 	DefaultAdminRoleNo = "role_554107924873216177918"
 
-	PtProtected PathType = "PROTECTED"
-	PtPublic    PathType = "PUBLIC"
+	// max number of items in one batch access test
+	maxBatchAccessTestItems = 500
+
+	PtProtected = policy.PtProtected
+	PtPublic    = policy.PtPublic
+
+	PrmAny = policy.PrmAny // any of the resources is required
+	PrmAll = policy.PrmAll // all of the resources are required
 )
 
 type PathRes struct {
@@ -53,14 +72,15 @@ type PathRes struct {
 }
 
 type ExtendedPathRes struct {
-	Id         int      // id
-	Pgroup     string   // path group
-	PathNo     string   // path no
-	ResCode    string   // resource code
-	Desc       string   // description
-	Url        string   // url
-	Method     string   // http method
-	Ptype      PathType // path type: PROTECTED, PUBLIC
+	Id         int         // id
+	Pgroup     string      // path group
+	PathNo     string      // path no
+	ResCode    string      // resource code
+	ResMode    PathResMode // resource mode: ANY, ALL
+	Desc       string      // description
+	Url        string      // url
+	Method     string      // http method
+	Ptype      PathType    // path type: PROTECTED, PUBLIC
 	CreateTime miso.ETime
 	CreateBy   string
 	UpdateTime miso.ETime
@@ -68,13 +88,17 @@ type ExtendedPathRes struct {
 }
 
 type EPath struct {
-	Id         int      // id
-	Pgroup     string   // path group
-	PathNo     string   // path no
-	Desc       string   // description
-	Url        string   // url
-	Method     string   // method
-	Ptype      PathType // path type: PROTECTED, PUBLIC
+	Id         int         // id
+	Pgroup     string      // path group
+	PathNo     string      // path no
+	Desc       string      // description
+	Url        string      // url
+	Method     string      // method
+	Ptype      PathType    // path type: PROTECTED, PUBLIC
+	ResMode    PathResMode // resource mode: ANY, ALL
+	Service    string      // service that reported the path
+	StaleSince *time.Time  // when the path is no longer reported by the service, nil if it's not stale
+	Manual     bool        // whether the url or method is edited manually, such paths are never marked stale
 	CreateTime miso.ETime
 	CreateBy   string
 	UpdateTime miso.ETime
@@ -85,6 +109,9 @@ type ERes struct {
 	Id         int    // id
 	Code       string // resource code
 	Name       string // resource name
+	Desc       string // description
+	Category   string // category
+	Service    string // service that reported the resource
 	CreateTime miso.ETime
 	CreateBy   string
 	UpdateTime miso.ETime
@@ -92,9 +119,31 @@ type ERes struct {
 }
 
 type ERoleRes struct {
+	Id         int        // id
+	RoleNo     string     // role no
+	ResCode    string     // resource code
+	ExpiresAt  *time.Time // when the grant expires, nil if it never expires
+	CreateTime miso.ETime
+	CreateBy   string
+	UpdateTime miso.ETime
+	UpdateBy   string
+}
+
+type ERoleParent struct {
+	Id           int    // id
+	RoleNo       string // role no
+	ParentRoleNo string // parent role no
+	CreateTime   miso.ETime
+	CreateBy     string
+	UpdateTime   miso.ETime
+	UpdateBy     string
+}
+
+type ERoleDeny struct {
 	Id         int    // id
 	RoleNo     string // role no
-	ResCode    string // resource code
+	ResCode    string // resource code, either ResCode or PathNo is present
+	PathNo     string // path no, either ResCode or PathNo is present
 	CreateTime miso.ETime
 	CreateBy   string
 	UpdateTime miso.ETime
@@ -105,6 +154,10 @@ type ERole struct {
 	Id         int
 	RoleNo     string
 	Name       string
+	Desc       string // description
+	Owner      string // owning team
+	Contact    string // contact of the owner
+	Tags       string // tags joined with ','
 	CreateTime miso.ETime
 	CreateBy   string
 	UpdateTime miso.ETime
@@ -115,6 +168,10 @@ type WRole struct {
 	Id         int        `json:"id"`
 	RoleNo     string     `json:"roleNo"`
 	Name       string     `json:"name"`
+	Desc       string     `json:"desc"`
+	Owner      string     `json:"owner"`
+	Contact    string     `json:"contact"`
+	Tags       []string   `json:"tags"`
 	CreateTime miso.ETime `json:"createTime"`
 	CreateBy   string     `json:"createBy"`
 	UpdateTime miso.ETime `json:"updateTime"`
@@ -122,36 +179,102 @@ type WRole struct {
 }
 
 type CachedUrlRes struct {
-	Id      int      // id
-	Pgroup  string   // path group
-	PathNo  string   // path no
-	ResCode string   // resource code
-	Url     string   // url
-	Method  string   // http method
-	Ptype   PathType // path type: PROTECTED, PUBLIC
+	Id       int         // id
+	Pgroup   string      // path group
+	PathNo   string      // path no
+	ResCodes []string    // resource codes
+	ResMode  PathResMode // resource mode: ANY, ALL
+	Url      string      // url
+	Method   string      // http method
+	Ptype    PathType    // path type: PROTECTED, PUBLIC
+}
+
+type CachedRoleDeny struct {
+	ResCodes []string // resources denied
+	PathNos  []string // paths denied
 }
 
 type ResBrief struct {
-	Code string `json:"code"`
-	Name string `json:"name"`
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	Category string `json:"category"`
 }
 
 type AddRoleReq struct {
-	Name string `json:"name" validation:"notEmpty,maxLen:32"` // role name
+	Name    string   `json:"name" validation:"notEmpty,maxLen:32"` // role name
+	Desc    string   `json:"desc" validation:"maxLen:255"`         // optional, description
+	Owner   string   `json:"owner" validation:"maxLen:64"`         // optional, owning team
+	Contact string   `json:"contact" validation:"maxLen:128"`      // optional, contact of the owner
+	Tags    []string `json:"tags"`                                 // optional, free-form tags
+}
+
+// Update role, the name is replaced, the metadata absent are unchanged
+type UpdateRoleReq struct {
+	RoleNo  string    `json:"roleNo" validation:"notEmpty"`
+	Name    string    `json:"name" validation:"notEmpty,maxLen:32"` // role name
+	Desc    *string   `json:"desc"`                                 // optional, description, unchanged if absent
+	Owner   *string   `json:"owner"`                                // optional, owning team, unchanged if absent
+	Contact *string   `json:"contact"`                              // optional, contact of the owner, unchanged if absent
+	Tags    *[]string `json:"tags"`                                 // optional, free-form tags, unchanged if absent
+}
+
+type DeleteRoleReq struct {
+	RoleNo string `json:"roleNo" validation:"notEmpty"`
+}
+
+type CloneRoleReq struct {
+	RoleNo string `json:"roleNo" validation:"notEmpty"`         // role no of the source role
+	Name   string `json:"name" validation:"notEmpty,maxLen:32"` // name of the new role
+}
+
+type CloneRoleResp struct {
+	RoleNo string `json:"roleNo"` // role no of the new role
 }
 
 type TestResAccessReq struct {
-	RoleNo string `json:"roleNo"`
-	Url    string `json:"url"`
-	Method string `json:"method"`
+	RoleNo  string   `json:"roleNo"`  // role no, multiple role nos can be joined with ','
+	RoleNos []string `json:"roleNos"` // role nos, merged with RoleNo
+	Url     string   `json:"url"`
+	Method  string   `json:"method"`
 }
 
 type TestResAccessResp struct {
 	Valid bool `json:"valid"`
 }
 
+type BatchTestResAccessReq struct {
+	RoleNo  string              `json:"roleNo"`  // role no, multiple role nos can be joined with ','
+	RoleNos []string            `json:"roleNos"` // role nos, merged with RoleNo
+	Items   []ResAccessTestItem `json:"items" validation:"notEmpty"`
+}
+
+type ResAccessTestItem struct {
+	Url    string `json:"url"`
+	Method string `json:"method"`
+}
+
+type BatchTestResAccessResp struct {
+	Items []ResAccessTestResult `json:"items"` // decisions, in the same order as the items requested
+}
+
+type ResAccessTestResult struct {
+	Url    string `json:"url"`
+	Method string `json:"method"`
+	Valid  bool   `json:"valid"`
+}
+
 type ListRoleReq struct {
-	Paging miso.Paging `json:"pagingVo"`
+	Name           string      `json:"name"`           // optional, fuzzy match on role name
+	RoleNo         string      `json:"roleNo"`         // optional, fuzzy match on role no
+	Owner          string      `json:"owner"`          // optional, owning team
+	Tag            string      `json:"tag"`            // optional, roles with the tag
+	CreateBy       string      `json:"createBy"`       // optional, who created the role
+	CreateTimeFrom *miso.ETime `json:"createTimeFrom"` // optional, inclusive
+	CreateTimeTo   *miso.ETime `json:"createTimeTo"`   // optional, exclusive
+	SortBy         string      `json:"sortBy"`         // optional, one of: id, roleNo, name, createTime, updateTime, defaults to id
+	SortOrder      string      `json:"sortOrder"`      // optional, ASC or DESC, defaults to DESC
+	Paging         miso.Paging `json:"pagingVo"`
 }
 
 type ListRoleResp struct {
@@ -164,32 +287,64 @@ type RoleBrief struct {
 	Name   string `json:"name"`
 }
 
+type DiffRoleReq struct {
+	RoleNoA string `json:"roleNoA" validation:"notEmpty"`
+	RoleNoB string `json:"roleNoB" validation:"notEmpty"`
+}
+
+type DiffRoleResp struct {
+	OnlyInA []DiffRes `json:"onlyInA"` // resources only accessible by role A
+	OnlyInB []DiffRes `json:"onlyInB"` // resources only accessible by role B
+	Both    []DiffRes `json:"both"`    // resources accessible by both roles
+}
+
+type DiffRes struct {
+	Code  string      `json:"code"`
+	Name  string      `json:"name"`
+	Paths []PathBrief `json:"paths"` // paths bound to the resource
+}
+
+type PathBrief struct {
+	PathNo string `json:"pathNo"`
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	Desc   string `json:"desc"`
+}
+
 type ListPathReq struct {
 	ResCode string      `json:"resCode"`
 	Pgroup  string      `json:"pgroup"`
 	Url     string      `json:"url"`
 	Ptype   PathType    `json:"ptype"`
+	Service string      `json:"service"` // optional, service that reported the path
+	Stale   bool        `json:"stale"`   // optional, only list paths no longer reported by the service
 	Paging  miso.Paging `json:"pagingVo"`
 }
 
 type WPath struct {
-	Id         int        `json:"id"`
-	Pgroup     string     `json:"pgroup"`
-	PathNo     string     `json:"pathNo"`
-	Method     string     `json:"method"`
-	Desc       string     `json:"desc"`
-	Url        string     `json:"url"`
-	Ptype      PathType   `json:"ptype"`
-	CreateTime miso.ETime `json:"createTime"`
-	CreateBy   string     `json:"createBy"`
-	UpdateTime miso.ETime `json:"updateTime"`
-	UpdateBy   string     `json:"updateBy"`
+	Id         int         `json:"id"`
+	Pgroup     string      `json:"pgroup"`
+	PathNo     string      `json:"pathNo"`
+	Method     string      `json:"method"`
+	Desc       string      `json:"desc"`
+	Url        string      `json:"url"`
+	Ptype      PathType    `json:"ptype"`
+	ResMode    PathResMode `json:"resMode"`
+	Service    string      `json:"service"`
+	StaleSince *miso.ETime `json:"staleSince"` // when the path is no longer reported by the service
+	CreateTime miso.ETime  `json:"createTime"`
+	CreateBy   string      `json:"createBy"`
+	UpdateTime miso.ETime  `json:"updateTime"`
+	UpdateBy   string      `json:"updateBy"`
 }
 
 type WRes struct {
 	Id         int        `json:"id"`
 	Code       string     `json:"code"`
 	Name       string     `json:"name"`
+	Desc       string     `json:"desc"`
+	Category   string     `json:"category"`
+	Service    string     `json:"service"`
 	CreateTime miso.ETime `json:"createTime"`
 	CreateBy   string     `json:"createBy"`
 	UpdateTime miso.ETime `json:"updateTime"`
@@ -202,8 +357,10 @@ type ListPathResp struct {
 }
 
 type BindPathResReq struct {
-	PathNo  string `json:"pathNo" validation:"notEmpty"`
-	ResCode string `json:"resCode" validation:"notEmpty"`
+	PathNo   string      `json:"pathNo" validation:"notEmpty"`
+	ResCode  string      `json:"resCode"`  // resource code
+	ResCodes []string    `json:"resCodes"` // resource codes, bound together with ResCode
+	ResMode  PathResMode `json:"resMode"`  // optional, resource mode of the path: ANY, ALL
 }
 
 type UnbindPathResReq struct {
@@ -222,8 +379,19 @@ type RemoveRoleResReq struct {
 }
 
 type AddRoleResReq struct {
-	RoleNo  string `json:"roleNo" validation:"notEmpty"`
-	ResCode string `json:"resCode" validation:"notEmpty"`
+	RoleNo    string      `json:"roleNo" validation:"notEmpty"`
+	ResCode   string      `json:"resCode" validation:"notEmpty"`
+	ExpiresAt *miso.ETime `json:"expiresAt"` // optional, when the grant expires, the grant never expires if absent
+}
+
+type BulkRoleResReq struct {
+	RoleNo   string   `json:"roleNo" validation:"notEmpty"`
+	ResCodes []string `json:"resCodes"` // resource codes
+}
+
+type BulkRoleResResp struct {
+	Added   []string `json:"added"`   // resources bound to the role
+	Removed []string `json:"removed"` // resources unbound from the role
 }
 
 type ListRoleResResp struct {
@@ -232,17 +400,70 @@ type ListRoleResResp struct {
 }
 
 type ListedRoleRes struct {
-	Id         int       `json:"id"`
-	ResCode    string    `json:"resCode"`
-	ResName    string    `json:"resName"`
-	CreateTime time.Time `json:"createTime"`
-	CreateBy   string    `json:"createBy"`
+	Id         int        `json:"id"`
+	ResCode    string     `json:"resCode"`
+	ResName    string     `json:"resName"`
+	ExpiresAt  *time.Time `json:"expiresAt"` // when the grant expires, null if it never expires
+	CreateTime time.Time  `json:"createTime"`
+	CreateBy   string     `json:"createBy"`
 }
 
 type RoleInfoReq struct {
 	RoleNo string `json:"roleNo" validation:"notEmpty"`
 }
 
+type AddRoleParentReq struct {
+	RoleNo       string `json:"roleNo" validation:"notEmpty"`
+	ParentRoleNo string `json:"parentRoleNo" validation:"notEmpty"`
+}
+
+type RemoveRoleParentReq struct {
+	RoleNo       string `json:"roleNo" validation:"notEmpty"`
+	ParentRoleNo string `json:"parentRoleNo" validation:"notEmpty"`
+}
+
+type ListRoleParentReq struct {
+	RoleNo string `json:"roleNo" validation:"notEmpty"`
+}
+
+type ListRoleParentResp struct {
+	Parents   []RoleBrief `json:"parents"`   // parent roles
+	Ancestors []RoleBrief `json:"ancestors"` // all roles inherited, including the parents
+}
+
+type AddRoleDenyReq struct {
+	RoleNo  string `json:"roleNo" validation:"notEmpty"`
+	ResCode string `json:"resCode"` // resource denied, either resCode or pathNo is required
+	PathNo  string `json:"pathNo"`  // path denied, either resCode or pathNo is required
+}
+
+type RemoveRoleDenyReq struct {
+	RoleNo  string `json:"roleNo" validation:"notEmpty"`
+	ResCode string `json:"resCode"`
+	PathNo  string `json:"pathNo"`
+}
+
+type ListRoleDenyReq struct {
+	Paging miso.Paging `json:"pagingVo"`
+	RoleNo string      `json:"roleNo" validation:"notEmpty"`
+}
+
+type ListRoleDenyResp struct {
+	Paging  miso.Paging      `json:"pagingVo"`
+	Payload []ListedRoleDeny `json:"payload"`
+}
+
+type ListedRoleDeny struct {
+	Id         int        `json:"id"`
+	ResCode    string     `json:"resCode"`
+	ResName    string     `json:"resName"`
+	PathNo     string     `json:"pathNo"`
+	Method     string     `json:"method"`
+	Url        string     `json:"url"`
+	CreateTime miso.ETime `json:"createTime"`
+	CreateBy   string     `json:"createBy"`
+}
+
 type GenResScriptReq struct {
 	ResCodes []string `json:"resCodes" validation:"notEmpty"`
 }
@@ -252,10 +473,18 @@ type RoleInfoResp struct {
 	Name   string `json:"name"`
 }
 
+// Update path, the path no is regenerated if the group, the method or the url is changed
 type UpdatePathReq struct {
 	Type   PathType `json:"type" validation:"notEmpty"`
 	PathNo string   `json:"pathNo" validation:"notEmpty"`
 	Group  string   `json:"group" validation:"notEmpty,maxLen:20"`
+	Desc   *string  `json:"desc"`                          // optional, unchanged if absent
+	Method string   `json:"method" validation:"maxLen:10"` // optional, unchanged if empty
+	Url    string   `json:"url" validation:"maxLen:128"`   // optional, unchanged if empty
+}
+
+type UpdatePathResp struct {
+	PathNo string `json:"pathNo"` // path no after the update
 }
 
 type CreatePathReq struct {
@@ -272,7 +501,16 @@ type DeletePathReq struct {
 }
 
 type ListResReq struct {
-	Paging miso.Paging `json:"pagingVo"`
+	Name           string      `json:"name"`           // optional, fuzzy match on resource name
+	Code           string      `json:"code"`           // optional, fuzzy match on resource code
+	Category       string      `json:"category"`       // optional, category of the resource
+	Service        string      `json:"service"`        // optional, service that reported the resource
+	CreateBy       string      `json:"createBy"`       // optional, who created the resource
+	CreateTimeFrom *miso.ETime `json:"createTimeFrom"` // optional, inclusive
+	CreateTimeTo   *miso.ETime `json:"createTimeTo"`   // optional, exclusive
+	SortBy         string      `json:"sortBy"`         // optional, one of: id, code, name, createTime, updateTime, defaults to id
+	SortOrder      string      `json:"sortOrder"`      // optional, ASC or DESC, defaults to DESC
+	Paging         miso.Paging `json:"pagingVo"`
 }
 
 type ListResResp struct {
@@ -285,25 +523,77 @@ type CreateResReq struct {
 	Code string `json:"code" validation:"notEmpty,maxLen:32"`
 }
 
+type UpdateResReq struct {
+	Code     string `json:"code" validation:"notEmpty"`
+	Name     string `json:"name" validation:"notEmpty,maxLen:32"`
+	Desc     string `json:"desc" validation:"maxLen:255"`
+	Category string `json:"category" validation:"maxLen:32"`
+}
+
 type DeleteResourceReq struct {
 	ResCode string `json:"resCode" validation:"notEmpty"`
 }
 
+// Update name, description and category of the resource, the code can't be changed
+func UpdateResource(ec miso.Rail, req UpdateResReq, user common.User) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Resource name is required")
+	}
+
+	_, e := lockResourceGlobal(ec, func() (any, error) {
+		var id int
+		tx := miso.GetMySQL().Raw(`select id from resource where code = ? and is_del = 0 limit 1`, req.Code).Scan(&id)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+		if id < 1 {
+			if err := resCodeCache.Del(ec, req.Code); err != nil {
+				ec.Errorf("failed to evict resCodeCache, %v, %v", req.Code, err)
+			}
+			return nil, miso.NewErrCode(ErrCodeResourceNotFound, "Resource not found")
+		}
+
+		tx = miso.GetMySQL().
+			Exec("update resource set name = ?, `desc` = ?, category = ?, update_by = ? where id = ?",
+				req.Name, strings.TrimSpace(req.Desc), strings.TrimSpace(req.Category), user.Username, id)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+
+		if err := resCodeCache.Put(ec, req.Code, "1"); err != nil {
+			ec.Errorf("failed to load resCodeCache, %v, %v", req.Code, err)
+		}
+		return nil, nil
+	})
+	return e
+}
+
 func DeleteResource(ec miso.Rail, req DeleteResourceReq) error {
 
+	// the resource and the bindings are deleted together, so that they can be restored together
+	delNo := genDelNo()
 	_, e := lockResourceGlobal(ec, func() (any, error) {
 		return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
-			if t := tx.Exec(`delete from resource where code = ?`, req.ResCode); t != nil {
+			if t := tx.Exec(`update resource set is_del = 1, del_no = ? where code = ? and is_del = 0`, delNo, req.ResCode); t.Error != nil {
 				return t.Error
 			}
-			if t := tx.Exec(`delete from role_resource where res_code = ?`, req.ResCode); t != nil {
+			if t := tx.Exec(`update role_resource set is_del = 1, del_no = ? where res_code = ? and is_del = 0`, delNo, req.ResCode); t.Error != nil {
 				return t.Error
 			}
-			return tx.Exec(`delete from path_resource where res_code = ?`, req.ResCode).Error
+			if t := tx.Exec(`update role_deny set is_del = 1, del_no = ? where res_code = ? and is_del = 0`, delNo, req.ResCode); t.Error != nil {
+				return t.Error
+			}
+			return tx.Exec(`update path_resource set is_del = 1, del_no = ? where res_code = ? and is_del = 0`, delNo, req.ResCode).Error
 		})
 	})
 
-	if e != nil {
+	if e == nil {
+		if err := resCodeCache.Del(ec, req.ResCode); err != nil {
+			ec.Errorf("failed to evict resCodeCache, %v, %v", req.ResCode, err)
+		}
+		publishAllChange(ec)
+
 		// asynchronously reload the cache of paths and resources
 		go func() {
 			if e := LoadPathResCache(ec); e != nil {
@@ -316,21 +606,31 @@ func DeleteResource(ec miso.Rail, req DeleteResourceReq) error {
 				ec.Errorf("Failed to load role resource cache, %v", e)
 			}
 		}()
+		return evictDenyOfDelNo(ec, delNo)
 	}
 
 	return e
 }
 
+// List resources that can be granted to the role, the ones already accessible by the role, i.e., granted directly, by
+// prefix grants or inherited from its ancestors, are excluded
 func ListResourceCandidatesForRole(ec miso.Rail, roleNo string) ([]ResBrief, error) {
 	if roleNo == "" {
 		return []ResBrief{}, nil
 	}
 
+	ancestors, e := listAncestorRoleNos(roleNo)
+	if e != nil {
+		return nil, e
+	}
+
 	var res []ResBrief
 	tx := miso.GetMySQL().
-		Select("r.name, r.code").
+		Select("r.name, r.code, r.desc, r.category").
 		Table("resource r").
-		Where("NOT EXISTS (SELECT * FROM role_resource WHERE role_no = ? and res_code = r.code)", roleNo).
+		Where("r.is_del = 0").
+		Where("NOT EXISTS (SELECT * FROM role_resource rr WHERE rr.role_no IN ? AND "+roleResMatchCond+
+			" AND rr.is_del = 0 AND (rr.expires_at IS NULL OR rr.expires_at > ?))", append([]string{roleNo}, ancestors...), time.Now()).
 		Scan(&res)
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -341,18 +641,43 @@ func ListResourceCandidatesForRole(ec miso.Rail, roleNo string) ([]ResBrief, err
 	return res, nil
 }
 
+// SQL condition that role_resource rr grants resource r, either by the exact code or by the prefix grant
+const roleResMatchCond = `(r.code = rr.res_code
+	OR (rr.res_code LIKE '%.*' AND LEFT(r.code, CHAR_LENGTH(rr.res_code) - 1) = LEFT(rr.res_code, CHAR_LENGTH(rr.res_code) - 1)))`
+
+// List resources of the role, see ListAllResBriefsOfRoles
 func ListAllResBriefsOfRole(ec miso.Rail, roleNo string) ([]ResBrief, error) {
+	return ListAllResBriefsOfRoles(ec, []string{roleNo})
+}
+
+// List resources of the roles, i.e., the union of resources of all the roles
+func ListAllResBriefsOfRoles(ec miso.Rail, roleNos []string) ([]ResBrief, error) {
 	var res []ResBrief
 
-	if roleNo == DefaultAdminRoleNo {
-		return ListAllResBriefs(ec)
+	if len(roleNos) < 1 {
+		return []ResBrief{}, nil
+	}
+
+	for _, roleNo := range roleNos {
+		if isAdminRole(roleNo) {
+			return ListAllResBriefs(ec)
+		}
+	}
+
+	// resources inherited from ancestors
+	ancestors, e := listAncestorRoleNos(roleNos...)
+	if e != nil {
+		return nil, e
 	}
+	// copied, the caller's slice is not modified
+	roleNos = append(append(make([]string, 0, len(roleNos)+len(ancestors)), roleNos...), ancestors...)
 
 	tx := miso.GetMySQL().
-		Select(`r.name, r.code`).
+		Select(`DISTINCT r.name, r.code, r.desc, r.category`).
 		Table(`role_resource rr`).
-		Joins(`LEFT JOIN resource r ON r.code = rr.res_code`).
-		Where(`rr.role_no = ?`, roleNo).
+		Joins(`JOIN resource r ON `+roleResMatchCond+` AND r.is_del = 0`).
+		Where(`rr.role_no IN ? AND rr.is_del = 0`, roleNos).
+		Where(`(rr.expires_at IS NULL OR rr.expires_at > ?)`, time.Now()).
 		Scan(&res)
 	if tx.Error != nil {
 		return nil, tx.Error
@@ -365,7 +690,7 @@ func ListAllResBriefsOfRole(ec miso.Rail, roleNo string) ([]ResBrief, error) {
 
 func ListAllResBriefs(ec miso.Rail) ([]ResBrief, error) {
 	var res []ResBrief
-	tx := miso.GetMySQL().Raw("select name, code from resource").Scan(&res)
+	tx := miso.GetMySQL().Raw("select name, code, `desc`, category from resource where is_del = 0").Scan(&res)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -375,113 +700,393 @@ func ListAllResBriefs(ec miso.Rail) ([]ResBrief, error) {
 	return res, nil
 }
 
-func ListResources(ec miso.Rail, req ListResReq) (ListResResp, error) {
-	var resources []WRes
-	tx := miso.GetMySQL().
-		Raw("select * from resource order by id desc limit ?, ?", req.Paging.GetOffset(), req.Paging.GetLimit()).
-		Scan(&resources)
-	if tx.Error != nil {
-		return ListResResp{}, tx.Error
+// Compare resources accessible by two roles, resources inherited are included
+func DiffRoles(ec miso.Rail, req DiffRoleReq) (DiffRoleResp, error) {
+	resA, e := ListAllResBriefsOfRoles(ec, []string{req.RoleNoA})
+	if e != nil {
+		return DiffRoleResp{}, e
 	}
-	if resources == nil {
-		resources = []WRes{}
+	resB, e := ListAllResBriefsOfRoles(ec, []string{req.RoleNoB})
+	if e != nil {
+		return DiffRoleResp{}, e
 	}
+	onlyA, onlyB, both := diffResBriefs(resA, resB)
 
-	var count int
-	tx = miso.GetMySQL().Raw("select count(*) from resource").Scan(&count)
-	if tx.Error != nil {
-		return ListResResp{}, tx.Error
+	codes := []string{}
+	for _, l := range [][]ResBrief{onlyA, onlyB, both} {
+		for _, r := range l {
+			codes = append(codes, r.Code)
+		}
+	}
+	paths, e := listPathBriefsOfRes(codes)
+	if e != nil {
+		return DiffRoleResp{}, e
 	}
 
-	return ListResResp{Paging: miso.RespPage(req.Paging, count), Payload: resources}, nil
+	toDiffRes := func(l []ResBrief) []DiffRes {
+		dr := make([]DiffRes, 0, len(l))
+		for _, r := range l {
+			p, ok := paths[r.Code]
+			if !ok {
+				p = []PathBrief{}
+			}
+			dr = append(dr, DiffRes{Code: r.Code, Name: r.Name, Paths: p})
+		}
+		return dr
+	}
+	return DiffRoleResp{OnlyInA: toDiffRes(onlyA), OnlyInB: toDiffRes(onlyB), Both: toDiffRes(both)}, nil
 }
 
-func UpdatePath(ec miso.Rail, req UpdatePathReq) error {
-	_, e := lockPath(ec, req.PathNo, func() (any, error) {
-		tx := miso.GetMySQL().Exec(`update path set pgroup = ?, ptype = ? where path_no = ?`,
-			req.Group, req.Type, req.PathNo)
-		return nil, tx.Error
-	})
+// Split resources into the ones only in a, only in b and in both, sorted by code
+func diffResBriefs(a []ResBrief, b []ResBrief) (onlyA []ResBrief, onlyB []ResBrief, both []ResBrief) {
+	inB := map[string]struct{}{}
+	for _, r := range b {
+		inB[r.Code] = struct{}{}
+	}
+	inA := map[string]struct{}{}
+	onlyA, onlyB, both = []ResBrief{}, []ResBrief{}, []ResBrief{}
 
-	if e == nil {
-		loadOnePathResCacheAsync(ec, req.PathNo)
+	for _, r := range a {
+		if _, ok := inA[r.Code]; ok {
+			continue
+		}
+		inA[r.Code] = struct{}{}
+		if _, ok := inB[r.Code]; ok {
+			both = append(both, r)
+		} else {
+			onlyA = append(onlyA, r)
+		}
 	}
-	return e
+	for _, r := range b {
+		if _, ok := inA[r.Code]; ok {
+			continue
+		}
+		inA[r.Code] = struct{}{} // dedupe
+		onlyB = append(onlyB, r)
+	}
+
+	for _, l := range [][]ResBrief{onlyA, onlyB, both} {
+		sort.Slice(l, func(i, j int) bool { return l[i].Code < l[j].Code })
+	}
+	return onlyA, onlyB, both
 }
 
-func loadOnePathResCacheAsync(ec miso.Rail, pathNo string) {
-	go func(ec miso.Rail, pathNo string) {
-		// ec.Infof("Refreshing path cache, pathNo: %s", pathNo)
-		ep, e := findPathRes(pathNo)
-		if e != nil {
-			ec.Errorf("Failed to reload path cache, pathNo: %s, %v", pathNo, e)
-			return
-		}
+// List paths bound to the resources, grouped by resource code
+func listPathBriefsOfRes(resCodes []string) (map[string][]PathBrief, error) {
+	grouped := map[string][]PathBrief{}
+	if len(resCodes) < 1 {
+		return grouped, nil
+	}
 
-		ep.Url = preprocessUrl(ep.Url)
-		if e := urlResCache.Put(ec, ep.Method+":"+ep.Url, toCachedUrlRes(ep)); e != nil {
-			ec.Errorf("Failed to save cached url resource, pathNo: %s, %v", pathNo, e)
-			return
+	var rows []struct {
+		ResCode string
+		PathBrief
+	}
+	tx := miso.GetMySQL().
+		Raw("select pr.res_code, p.path_no, p.method, p.url, p.`desc` from path_resource pr "+
+			"left join path p on pr.path_no = p.path_no and p.is_del = 0 "+
+			"where pr.res_code in ? and pr.is_del = 0 order by p.url", resCodes).
+		Scan(&rows)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	for _, r := range rows {
+		if r.PathNo == "" { // path is already deleted
+			continue
 		}
-	}(ec, pathNo)
+		grouped[r.ResCode] = append(grouped[r.ResCode], r.PathBrief)
+	}
+	return grouped, nil
 }
 
-func GetRoleInfo(ec miso.Rail, req RoleInfoReq) (RoleInfoResp, error) {
-	resp, err := roleInfoCache.Get(ec, req.RoleNo, func() (RoleInfoResp, error) {
-		var resp RoleInfoResp
-		tx := miso.GetMySQL().Raw("select role_no, name from role where role_no = ?", req.RoleNo).Scan(&resp)
-		if tx.Error != nil {
-			return resp, tx.Error
-		}
+var (
+	resSortColumns = map[string]string{
+		"id":         "id",
+		"code":       "code",
+		"name":       "name",
+		"createTime": "create_time",
+		"updateTime": "update_time",
+	}
+	roleSortColumns = map[string]string{
+		"id":         "id",
+		"roleNo":     "role_no",
+		"name":       "name",
+		"createTime": "create_time",
+		"updateTime": "update_time",
+	}
+)
 
-		if tx.RowsAffected < 1 {
-			return resp, miso.NewErr(ErrCodeRoleNotFound, "Role not found")
+// Build order by clause, sortBy must be one of the columns, the order defaults to 'id DESC'
+func orderClause(sortBy string, sortOrder string, columns map[string]string) (string, error) {
+	col := "id"
+	if sortBy = strings.TrimSpace(sortBy); sortBy != "" {
+		c, ok := columns[sortBy]
+		if !ok {
+			return "", miso.NewErrCode(ErrCodeIllegalArgument, fmt.Sprintf("Can't sort by '%s'", sortBy))
 		}
-		return resp, nil
-	})
-	return resp, err
+		col = c
+	}
+
+	order := "DESC"
+	switch strings.ToUpper(strings.TrimSpace(sortOrder)) {
+	case "", "DESC":
+	case "ASC":
+		order = "ASC"
+	default:
+		return "", miso.NewErrCode(ErrCodeIllegalArgument, fmt.Sprintf("Invalid sort order '%s'", sortOrder))
+	}
+
+	if col == "id" {
+		return "id " + order, nil
+	}
+	return col + " " + order + ", id " + order, nil // id breaks the ties, so paging is stable
 }
 
-func CreateResourceIfNotExist(rail miso.Rail, req CreateResReq, user common.User) error {
-	req.Name = strings.TrimSpace(req.Name)
-	req.Code = strings.TrimSpace(req.Code)
+// Filter by create_by and create_time
+func applyCreatedCond(t *gorm.DB, createBy string, from *miso.ETime, to *miso.ETime) *gorm.DB {
+	if createBy != "" {
+		t = t.Where("create_by = ?", createBy)
+	}
+	if from != nil {
+		t = t.Where("create_time >= ?", time.Time(*from))
+	}
+	if to != nil {
+		t = t.Where("create_time < ?", time.Time(*to))
+	}
+	return t
+}
 
-	ok, err := resCodeCache.Exists(rail, req.Code)
-	if err != nil {
-		rail.Errorf("failed to lookup resCode from resCodeCache, %v, %v", req.Code, err)
-	} else if ok {
-		rail.Debugf("Resource '%s' already exist", req.Code)
-		return nil
+func ListResources(ec miso.Rail, req ListResReq) (ListResResp, error) {
+	order, e := orderClause(req.SortBy, req.SortOrder, resSortColumns)
+	if e != nil {
+		return ListResResp{}, e
 	}
 
-	_, e := lockResourceGlobal(rail, func() (any, error) {
-		var id int
-		tx := miso.GetMySQL().Raw(`select id from resource where code = ? limit 1`, req.Code).Scan(&id)
-		if tx.Error != nil {
-			return nil, tx.Error
+	applyCond := func(t *gorm.DB) *gorm.DB {
+		t = t.Where("is_del = 0")
+		if req.Name != "" {
+			t = t.Where("name LIKE ?", "%"+req.Name+"%")
 		}
-
-		if id > 0 {
-			if err := resCodeCache.Put(rail, req.Code, "1"); err != nil {
-				rail.Errorf("failed to load resCodeCache, %v, %v", req.Code, err)
-			}
-			rail.Debugf("Resource '%s' (%s) already exist", req.Code, req.Name)
-			return nil, nil
+		if req.Code != "" {
+			t = t.Where("code LIKE ?", "%"+req.Code+"%")
 		}
-
-		res := ERes{
-			Name:     req.Name,
-			Code:     req.Code,
-			CreateBy: user.Username,
-			UpdateBy: user.Username,
+		if req.Category != "" {
+			t = t.Where("category = ?", req.Category)
 		}
+		if req.Service != "" {
+			t = t.Where("service = ?", req.Service)
+		}
+		return applyCreatedCond(t, req.CreateBy, req.CreateTimeFrom, req.CreateTimeTo)
+	}
 
-		tx = miso.GetMySQL().
-			Table("resource").
-			Omit("Id", "CreateTime", "UpdateTime").
-			Create(&res)
+	var resources []WRes
+	tx := miso.GetMySQL().
+		Table("resource").
+		Order(order)
 
+	tx = applyCond(tx).
+		Offset(req.Paging.GetOffset()).
+		Limit(req.Paging.GetLimit()).
+		Scan(&resources)
+	if tx.Error != nil {
+		return ListResResp{}, tx.Error
+	}
+	if resources == nil {
+		resources = []WRes{}
+	}
+
+	var count int
+	tx = miso.GetMySQL().
+		Table("resource").
+		Select("COUNT(*)")
+
+	tx = applyCond(tx).
+		Scan(&count)
+	if tx.Error != nil {
+		return ListResResp{}, tx.Error
+	}
+
+	return ListResResp{Paging: miso.RespPage(req.Paging, count), Payload: resources}, nil
+}
+
+func UpdatePath(ec miso.Rail, req UpdatePathReq) (UpdatePathResp, error) {
+	req.PathNo = strings.TrimSpace(req.PathNo)
+	req.Group = strings.TrimSpace(req.Group)
+	if req.Desc != nil {
+		desc := strings.TrimSpace(*req.Desc)
+		if len([]rune(desc)) > 255 {
+			return UpdatePathResp{}, miso.NewErrCode(ErrCodeIllegalArgument, "Description is too long")
+		}
+		req.Desc = &desc
+	}
+
+	var prev EPath
+	var pathNo, method, url string
+	_, e := lockPath(ec, req.PathNo, func() (any, error) {
+		tx := miso.GetMySQL().Raw(`select * from path where path_no = ? and is_del = 0 limit 1`, req.PathNo).Scan(&prev)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+		if prev.Id < 1 {
+			return nil, miso.NewErrCode(ErrCodePathNotFound, "Path not found")
+		}
+
+		method, url = prev.Method, prev.Url
+		desc := prev.Desc
+		if m := strings.ToUpper(strings.TrimSpace(req.Method)); m != "" {
+			method = m
+		}
+		if strings.TrimSpace(req.Url) != "" {
+			url = policy.PreprocessUrl(req.Url)
+		}
+		if req.Desc != nil {
+			desc = *req.Desc
+		}
+
+		pathNo = genPathNo(req.Group, url, method)
+		if pathNo == prev.PathNo {
+			tx := miso.GetMySQL().Exec("update path set pgroup = ?, ptype = ?, `desc` = ? where id = ?",
+				req.Group, req.Type, desc, prev.Id)
+			return nil, tx.Error
+		}
+
+		// path no is changed, the bindings are migrated to the new path no
+		return lockPath(ec, pathNo, func() (any, error) {
+			var id int
+			tx := miso.GetMySQL().Raw(`select id from path where path_no = ? and is_del = 0 limit 1`, pathNo).Scan(&id)
+			if tx.Error != nil {
+				return nil, tx.Error
+			}
+			if id > 0 {
+				return nil, miso.NewErrCode(ErrCodeIllegalState, "Path already exists")
+			}
+
+			return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+				// the path is no longer the one reported by the service, it's exempted from stale pruning
+				t := tx.Exec("update path set path_no = ?, pgroup = ?, ptype = ?, `desc` = ?, method = ?, url = ?, manual = 1, stale_since = null where id = ?",
+					pathNo, req.Group, req.Type, desc, method, url, prev.Id)
+				if t.Error != nil {
+					return t.Error
+				}
+				if t := tx.Exec(`update path_resource set path_no = ? where path_no = ? and is_del = 0`, pathNo, prev.PathNo); t.Error != nil {
+					return t.Error
+				}
+				return tx.Exec(`update role_deny set path_no = ? where path_no = ? and is_del = 0`, pathNo, prev.PathNo).Error
+			})
+		})
+	})
+	if e != nil {
+		return UpdatePathResp{}, e
+	}
+
+	if pathNo != prev.PathNo {
+		ec.Infof("Path %s '%s %s' is changed to %s", prev.PathNo, prev.Method, prev.Url, pathNo)
+
+		if err := urlResCache.Del(ec, prev.Method+":"+prev.Url); err != nil {
+			ec.Errorf("failed to evict urlResCache, %v, %v", prev.PathNo, err)
+		}
+		if err := pathNoCache.Del(ec, prev.PathNo); err != nil {
+			ec.Errorf("failed to evict pathNoCache, %v, %v", prev.PathNo, err)
+		}
+		if policy.IsUrlPattern(prev.Url) {
+			evictUrlPatternCache(ec, prev.Method)
+		}
+		if policy.IsUrlPattern(url) {
+			evictUrlPatternCache(ec, method)
+		}
+		publishPathChange(ec, prev.PathNo)
+
+		// deny rules of the path are cached by path no
+		var roleNos []string
+		tx := miso.GetMySQL().Raw(`select distinct role_no from role_deny where path_no = ? and is_del = 0`, pathNo).Scan(&roleNos)
+		if tx.Error != nil {
+			return UpdatePathResp{}, tx.Error
+		}
+		if e := evictDenyOfRoles(ec, roleNos); e != nil {
+			return UpdatePathResp{}, e
+		}
+	}
+
+	loadOnePathResCacheAsync(ec, pathNo)
+	return UpdatePathResp{PathNo: pathNo}, nil
+}
+
+func loadOnePathResCacheAsync(ec miso.Rail, pathNo string) {
+	go func(ec miso.Rail, pathNo string) {
+		// ec.Infof("Refreshing path cache, pathNo: %s", pathNo)
+		cur, e := findPathRes(pathNo)
+		if e != nil {
+			ec.Errorf("Failed to reload path cache, pathNo: %s, %v", pathNo, e)
+			return
+		}
+
+		if e := urlResCache.Put(ec, cur.Method+":"+cur.Url, cur); e != nil {
+			ec.Errorf("Failed to save cached url resource, pathNo: %s, %v", pathNo, e)
+			return
+		}
+		publishPathChange(ec, pathNo)
+	}(ec, pathNo)
+}
+
+func GetRoleInfo(ec miso.Rail, req RoleInfoReq) (RoleInfoResp, error) {
+	resp, err := roleInfoCache.Get(ec, req.RoleNo, func() (RoleInfoResp, error) {
+		var resp RoleInfoResp
+		tx := miso.GetMySQL().Raw("select role_no, name from role where role_no = ? and is_del = 0", req.RoleNo).Scan(&resp)
+		if tx.Error != nil {
+			return resp, tx.Error
+		}
+
+		if tx.RowsAffected < 1 {
+			return resp, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+		}
+		return resp, nil
+	})
+	return resp, err
+}
+
+// Create resource if not exist, service is the name of the service that reported the resource, it may be empty
+func CreateResourceIfNotExist(rail miso.Rail, req CreateResReq, service string, user common.User) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Code = strings.TrimSpace(req.Code)
+	service = strings.TrimSpace(service)
+
+	ok, err := resCodeCache.Exists(rail, req.Code)
+	if err != nil {
+		rail.Errorf("failed to lookup resCode from resCodeCache, %v, %v", req.Code, err)
+	} else if ok {
+		rail.Debugf("Resource '%s' already exist", req.Code)
+		return nil
+	}
+
+	_, e := lockResourceGlobal(rail, func() (any, error) {
+		var id int
+		tx := miso.GetMySQL().Raw(`select id from resource where code = ? and is_del = 0 limit 1`, req.Code).Scan(&id)
 		if tx.Error != nil {
+			return nil, tx.Error
+		}
+
+		if id > 0 {
+			if err := resCodeCache.Put(rail, req.Code, "1"); err != nil {
+				rail.Errorf("failed to load resCodeCache, %v, %v", req.Code, err)
+			}
+			rail.Debugf("Resource '%s' (%s) already exist", req.Code, req.Name)
+			return nil, nil
+		}
+
+		res := ERes{
+			Name:     req.Name,
+			Code:     req.Code,
+			Service:  service,
+			CreateBy: user.Username,
+			UpdateBy: user.Username,
+		}
+
+		tx = miso.GetMySQL().
+			Table("resource").
+			Omit("Id", "CreateTime", "UpdateTime").
+			Create(&res)
+
+		if tx.Error == nil {
 			if err := resCodeCache.Put(rail, req.Code, "1"); err != nil {
 				rail.Errorf("failed to load resCodeCache, %v, %v", req.Code, err)
 			}
@@ -497,9 +1102,14 @@ func genPathNo(group string, url string, method string) string {
 	return "path_" + base64.StdEncoding.EncodeToString(cksum[:])
 }
 
-func CreatePathIfNotExist(rail miso.Rail, req CreatePathReq, user common.User) error {
-	req.Url = preprocessUrl(req.Url)
+// Create path if not exist, service is the name of the service that reported the path, the path group is used if it's empty
+func CreatePathIfNotExist(rail miso.Rail, req CreatePathReq, service string, user common.User) error {
+	req.Url = policy.PreprocessUrl(req.Url)
 	req.Group = strings.TrimSpace(req.Group)
+	service = strings.TrimSpace(service)
+	if service == "" {
+		service = req.Group
+	}
 	req.Method = strings.ToUpper(strings.TrimSpace(req.Method))
 	pathNo := genPathNo(req.Group, req.Url, req.Method)
 
@@ -513,7 +1123,7 @@ func CreatePathIfNotExist(rail miso.Rail, req CreatePathReq, user common.User) e
 
 	res, e := lockPath(rail, pathNo, func() (any, error) {
 		var id int
-		tx := miso.GetMySQL().Raw(`select id from path where path_no = ? limit 1`, pathNo).Scan(&id)
+		tx := miso.GetMySQL().Raw(`select id from path where path_no = ? and is_del = 0 limit 1`, pathNo).Scan(&id)
 		if tx.Error != nil {
 			return false, tx.Error
 		}
@@ -532,6 +1142,8 @@ func CreatePathIfNotExist(rail miso.Rail, req CreatePathReq, user common.User) e
 			Pgroup:   req.Group,
 			Method:   req.Method,
 			PathNo:   pathNo,
+			ResMode:  PrmAny,
+			Service:  service,
 			CreateBy: user.Username,
 			UpdateBy: user.Username,
 		}
@@ -558,6 +1170,10 @@ func CreatePathIfNotExist(rail miso.Rail, req CreatePathReq, user common.User) e
 	created := res.(bool)
 	if created { // reload cache for the path
 		loadOnePathResCacheAsync(rail, pathNo)
+
+		if policy.IsUrlPattern(req.Url) {
+			evictUrlPatternCache(rail, req.Method)
+		}
 	}
 
 	if req.ResCode != "" { // rebind path and resource
@@ -569,76 +1185,125 @@ func CreatePathIfNotExist(rail miso.Rail, req CreatePathReq, user common.User) e
 
 func DeletePath(ec miso.Rail, req DeletePathReq) error {
 	req.PathNo = strings.TrimSpace(req.PathNo)
-	_, e := lockPath(ec, req.PathNo, func() (any, error) {
+
+	// the path and the bindings are deleted together, so that they can be restored together
+	delNo := genDelNo()
+	res, e := lockPath(ec, req.PathNo, func() (any, error) {
+		var ep EPath
+		tx := miso.GetMySQL().Raw(`select * from path where path_no = ? and is_del = 0 limit 1`, req.PathNo).Scan(&ep)
+		if tx.Error != nil {
+			return ep, tx.Error
+		}
+
 		er := miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
-			tx = tx.Exec(`delete from path where path_no = ?`, req.PathNo)
+			tx = tx.Exec(`update path set is_del = 1, del_no = ? where path_no = ? and is_del = 0`, delNo, req.PathNo)
 			if tx.Error != nil {
 				return tx.Error
 			}
 
-			return tx.Exec(`delete from path_resource where path_no = ?`, req.PathNo).Error
+			if err := tx.Exec(`update role_deny set is_del = 1, del_no = ? where path_no = ? and is_del = 0`, delNo, req.PathNo).Error; err != nil {
+				return err
+			}
+
+			return tx.Exec(`update path_resource set is_del = 1, del_no = ? where path_no = ? and is_del = 0`, delNo, req.PathNo).Error
 		})
 
-		return nil, er
+		return ep, er
 	})
-	return e
+	if e != nil {
+		return e
+	}
+
+	if ep := res.(EPath); ep.PathNo != "" {
+		if err := urlResCache.Del(ec, ep.Method+":"+ep.Url); err != nil {
+			ec.Errorf("failed to evict urlResCache, %v, %v", ep.PathNo, err)
+		}
+		if policy.IsUrlPattern(ep.Url) {
+			evictUrlPatternCache(ec, ep.Method)
+		}
+	}
+	if err := pathNoCache.Del(ec, req.PathNo); err != nil {
+		ec.Errorf("failed to evict pathNoCache, %v, %v", req.PathNo, err)
+	}
+	publishPathChange(ec, req.PathNo)
+	return evictDenyOfDelNo(ec, delNo)
 }
 
 func UnbindPathRes(ec miso.Rail, req UnbindPathResReq) error {
 	req.PathNo = strings.TrimSpace(req.PathNo)
+	req.ResCode = strings.TrimSpace(req.ResCode)
 	_, e := lockPath(ec, req.PathNo, func() (any, error) {
-		tx := miso.GetMySQL().Exec(`delete from path_resource where path_no = ?`, req.PathNo)
+		tx := miso.GetMySQL().Exec(`update path_resource set is_del = 1 where path_no = ? and res_code = ? and is_del = 0`, req.PathNo, req.ResCode)
 		return nil, tx.Error
 	})
 
-	if e != nil {
+	if e == nil {
 		// asynchronously reload the cache of paths and resources
-		go func() {
-			if e := LoadPathResCache(ec); e != nil {
-				ec.Errorf("Failed to load path resource cache, %v", e)
-			}
-		}()
+		loadOnePathResCacheAsync(ec, req.PathNo)
 	}
 	return e
 }
 
 func BindPathRes(rail miso.Rail, req BindPathResReq) error {
 	req.PathNo = strings.TrimSpace(req.PathNo)
-	e := lockPathExec(rail, req.PathNo, func() error { // lock for path
-		return lockResourceGlobalExec(rail, func() error {
 
-			// check if resource exist
-			var resId int
-			tx := miso.GetMySQL().
-				Raw(`SELECT id FROM resource WHERE code = ?`, req.ResCode).
-				Scan(&resId)
-			if tx.Error != nil {
-				return tx.Error
-			}
-			if resId < 1 {
-				rail.Errorf("Resource %v not found", req.ResCode)
-				return miso.NewErr("Resource not found")
-			}
+	resCodes := []string{}
+	for _, code := range append([]string{req.ResCode}, req.ResCodes...) {
+		if code = strings.TrimSpace(code); code != "" {
+			resCodes = append(resCodes, code)
+		}
+	}
 
-			// check if the path is already bound to current resource
-			var prid int
-			tx = miso.GetMySQL().
-				Raw(`SELECT id FROM path_resource WHERE path_no = ? AND res_code = ? LIMIT 1`, req.PathNo, req.ResCode).
-				Scan(&prid)
+	if req.ResMode != "" && req.ResMode != PrmAny && req.ResMode != PrmAll {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Invalid resource mode")
+	}
+	if len(resCodes) < 1 && req.ResMode == "" {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Resource code is required")
+	}
 
-			if tx.Error != nil {
-				rail.Errorf("Failed to bind path %v to resource %v, %v", req.PathNo, req.ResCode, tx.Error)
-				return tx.Error
-			}
-			if prid > 0 {
-				rail.Debugf("Path %v already bound to resource %v", req.PathNo, req.ResCode)
-				return tx.Error
-			}
+	e := lockPathExec(rail, req.PathNo, func() error { // lock for path
+		return lockResourceGlobalExec(rail, func() error {
 
-			// bind resource to path
-			return miso.GetMySQL().
-				Exec(`INSERT INTO path_resource (path_no, res_code) VALUES (?, ?)`, req.PathNo, req.ResCode).
-				Error
+			return miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+				for _, resCode := range resCodes {
+
+					// check if resource exist
+					var resId int
+					t := tx.Raw(`SELECT id FROM resource WHERE code = ? AND is_del = 0`, resCode).
+						Scan(&resId)
+					if t.Error != nil {
+						return t.Error
+					}
+					if resId < 1 {
+						rail.Errorf("Resource %v not found", resCode)
+						return miso.NewErrCode(ErrCodeResourceNotFound, "Resource not found")
+					}
+
+					// check if the path is already bound to current resource
+					var prid int
+					t = tx.Raw(`SELECT id FROM path_resource WHERE path_no = ? AND res_code = ? AND is_del = 0 LIMIT 1`, req.PathNo, resCode).
+						Scan(&prid)
+
+					if t.Error != nil {
+						rail.Errorf("Failed to bind path %v to resource %v, %v", req.PathNo, resCode, t.Error)
+						return t.Error
+					}
+					if prid > 0 {
+						rail.Debugf("Path %v already bound to resource %v", req.PathNo, resCode)
+						continue
+					}
+
+					// bind resource to path
+					if err := tx.Exec(`INSERT INTO path_resource (path_no, res_code) VALUES (?, ?)`, req.PathNo, resCode).Error; err != nil {
+						return err
+					}
+				}
+
+				if req.ResMode != "" {
+					return tx.Exec(`UPDATE path SET res_mode = ? WHERE path_no = ? AND is_del = 0`, req.ResMode, req.PathNo).Error
+				}
+				return nil
+			})
 		})
 	})
 
@@ -652,11 +1317,12 @@ func BindPathRes(rail miso.Rail, req BindPathResReq) error {
 func ListPaths(ec miso.Rail, req ListPathReq) (ListPathResp, error) {
 
 	applyCond := func(t *gorm.DB) *gorm.DB {
+		t = t.Where("p.is_del = 0")
 		if req.Pgroup != "" {
 			t = t.Where("p.pgroup = ?", req.Pgroup)
 		}
 		if req.ResCode != "" {
-			t = t.Joins("LEFT JOIN path_resource pr ON p.path_no = pr.path_no").
+			t = t.Joins("LEFT JOIN path_resource pr ON p.path_no = pr.path_no AND pr.is_del = 0").
 				Where("pr.res_code = ?", req.ResCode)
 		}
 		if req.Url != "" {
@@ -665,6 +1331,12 @@ func ListPaths(ec miso.Rail, req ListPathReq) (ListPathResp, error) {
 		if req.Ptype != "" {
 			t = t.Where("p.ptype = ?", req.Ptype)
 		}
+		if req.Service != "" {
+			t = t.Where("p.service = ?", req.Service)
+		}
+		if req.Stale {
+			t = t.Where("p.stale_since IS NOT NULL")
+		}
 		return t
 	}
 
@@ -698,10 +1370,19 @@ func ListPaths(ec miso.Rail, req ListPathReq) (ListPathResp, error) {
 }
 
 func AddRole(ec miso.Rail, req AddRoleReq, user common.User) error {
-	_, e := miso.RLockRun(ec, "goauth:role:add"+req.Name, func() (any, error) {
+	tags, e := joinRoleTags(req.Tags)
+	if e != nil {
+		return e
+	}
+
+	_, e = miso.RLockRun(ec, "goauth:role:add"+req.Name, func() (any, error) {
 		r := ERole{
 			RoleNo:   miso.GenIdP("role_"),
 			Name:     req.Name,
+			Desc:     strings.TrimSpace(req.Desc),
+			Owner:    strings.TrimSpace(req.Owner),
+			Contact:  strings.TrimSpace(req.Contact),
+			Tags:     tags,
 			CreateBy: user.Username,
 			UpdateBy: user.Username,
 		}
@@ -713,191 +1394,1314 @@ func AddRole(ec miso.Rail, req AddRoleReq, user common.User) error {
 	return e
 }
 
-func RemoveResFromRole(ec miso.Rail, req RemoveRoleResReq) error {
-	_, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) {
-		tx := miso.GetMySQL().Exec(`delete from role_resource where role_no = ? and res_code = ?`, req.RoleNo, req.ResCode)
-		return nil, tx.Error
-	})
-
-	if e != nil {
-		e = roleResCache.Put(ec, fmt.Sprintf("role:%s:res:%s", req.RoleNo, req.ResCode), "")
+// Clone role, the new role has all the resources of the source role
+func CloneRole(ec miso.Rail, req CloneRoleReq, user common.User) (CloneRoleResp, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return CloneRoleResp{}, miso.NewErrCode(ErrCodeIllegalArgument, "Role name is required")
 	}
 
-	return e
-}
-
-func AddResToRoleIfNotExist(ec miso.Rail, req AddRoleResReq, user common.User) error {
-
-	res, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for role
-		return lockResourceGlobal(ec, func() (any, error) {
-			// check if resource exist
-			var resId int
-			tx := miso.GetMySQL().Raw(`select id from resource where code = ?`, req.ResCode).Scan(&resId)
-			if tx.Error != nil {
-				return false, tx.Error
-			}
-			if resId < 1 {
-				return false, miso.NewErr("Resource not found")
-			}
-
-			// check if role-resource relation exists
-			var id int
-			tx = miso.GetMySQL().Raw(`select id from role_resource where role_no = ? and res_code = ?`, req.RoleNo, req.ResCode).Scan(&id)
-			if tx.Error != nil {
-				return false, tx.Error
-			}
-			if id > 0 { // relation exists already
-				return false, nil
-			}
+	roleNo := miso.GenIdP("role_")
+	_, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for source role
+		var src ERole
+		tx := miso.GetMySQL().Raw(`select * from role where role_no = ? and is_del = 0 limit 1`, req.RoleNo).Scan(&src)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+		if src.Id < 1 {
+			return nil, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+		}
 
-			// create role-resource relation
-			rr := ERoleRes{
-				RoleNo:   req.RoleNo,
-				ResCode:  req.ResCode,
+		return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+			r := ERole{
+				RoleNo:   roleNo,
+				Name:     req.Name,
+				Desc:     src.Desc,
+				Owner:    src.Owner,
+				Contact:  src.Contact,
+				Tags:     src.Tags,
 				CreateBy: user.Username,
 				UpdateBy: user.Username,
 			}
+			if t := tx.Table("role").Omit("Id", "CreateTime", "UpdateTime").Create(&r); t.Error != nil {
+				return t.Error
+			}
 
-			return true, miso.GetMySQL().
-				Table("role_resource").
-				Omit("Id", "CreateTime", "UpdateTime").
-				Create(&rr).Error
+			return tx.Exec(`insert into role_resource (role_no, res_code, expires_at, create_by, update_by)
+				select ?, res_code, expires_at, ?, ? from role_resource where role_no = ? and is_del = 0`,
+				roleNo, user.Username, user.Username, req.RoleNo).Error
 		})
 	})
-
 	if e != nil {
-		return e
+		return CloneRoleResp{}, e
 	}
+	ec.Infof("Cloned role %s as %s", req.RoleNo, roleNo)
 
-	if isAdded := res.(bool); isAdded {
-		e = _loadResOfRole(ec, req.RoleNo)
+	if e := _loadResOfRole(ec, roleNo); e != nil {
+		return CloneRoleResp{}, e
 	}
-
-	return e
+	publishRoleChange(ec, roleNo)
+	return CloneRoleResp{RoleNo: roleNo}, nil
 }
 
-func ListRoleRes(ec miso.Rail, req ListRoleResReq) (ListRoleResResp, error) {
-	var res []ListedRoleRes
-	tx := miso.GetMySQL().
-		Raw(`select rr.id, rr.res_code, rr.create_time, rr.create_by, r.name 'res_name' from role_resource rr
-			left join resource r on rr.res_code = r.code
-			where rr.role_no = ? order by rr.id desc limit ?, ?`, req.RoleNo, req.Paging.GetOffset(), req.Paging.GetLimit()).
-		Scan(&res)
-
-	if tx.Error != nil {
-		return ListRoleResResp{}, tx.Error
+func UpdateRole(ec miso.Rail, req UpdateRoleReq, user common.User) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Role name is required")
 	}
-
-	if res == nil {
-		res = []ListedRoleRes{}
+	desc, e := trimOptionalStr(req.Desc, 255, "Description is too long")
+	if e != nil {
+		return e
 	}
-
-	var count int
-	tx = miso.GetMySQL().
-		Raw(`select count(*) from role_resource rr
-			left join resource r on rr.res_code = r.code
-			where rr.role_no = ?`, req.RoleNo).
-		Scan(&count)
-
-	if tx.Error != nil {
-		return ListRoleResResp{}, tx.Error
+	owner, e := trimOptionalStr(req.Owner, 64, "Owner is too long")
+	if e != nil {
+		return e
+	}
+	contact, e := trimOptionalStr(req.Contact, 128, "Contact is too long")
+	if e != nil {
+		return e
+	}
+	var tags *string
+	if req.Tags != nil {
+		joined, e := joinRoleTags(*req.Tags)
+		if e != nil {
+			return e
+		}
+		tags = &joined
 	}
 
-	return ListRoleResResp{Payload: res, Paging: miso.Paging{Limit: req.Paging.Limit, Page: req.Paging.Page, Total: count}}, nil
-}
+	_, e = miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for role
+		var prev ERole
+		tx := miso.GetMySQL().Raw(`select * from role where role_no = ? and is_del = 0 limit 1`, req.RoleNo).Scan(&prev)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+		if prev.Id < 1 {
+			return nil, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+		}
+
+		// fields absent are unchanged
+		if desc != nil {
+			prev.Desc = *desc
+		}
+		if owner != nil {
+			prev.Owner = *owner
+		}
+		if contact != nil {
+			prev.Contact = *contact
+		}
+		if tags != nil {
+			prev.Tags = *tags
+		}
+
+		return nil, miso.GetMySQL().
+			Exec("update role set name = ?, `desc` = ?, owner = ?, contact = ?, tags = ?, update_by = ? where id = ?",
+				req.Name, prev.Desc, prev.Owner, prev.Contact, prev.Tags, user.Username, prev.Id).
+			Error
+	})
+	if e != nil {
+		return e
+	}
+
+	return roleInfoCache.Del(ec, req.RoleNo)
+}
+
+// Delete role, the resources, deny rules and inheritance of the role are deleted as well
+func DeleteRole(ec miso.Rail, req DeleteRoleReq) error {
+	if isAdminRole(req.RoleNo) {
+		return miso.NewErrCode(ErrCodeIllegalState, "Administrator role can't be deleted")
+	}
+
+	var children []string
+	var inherited []string
+
+	_, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for role
+		return lockRoleParent(ec, func() (any, error) {
+			var id int
+			tx := miso.GetMySQL().Raw(`select id from role where role_no = ? and is_del = 0 limit 1`, req.RoleNo).Scan(&id)
+			if tx.Error != nil {
+				return nil, tx.Error
+			}
+			if id < 1 {
+				return nil, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+			}
+
+			// children that inherit from the role, and resources they may no longer have access to
+			tx = miso.GetMySQL().Raw(`select role_no from role_parent where parent_role_no = ? and is_del = 0`, req.RoleNo).Scan(&children)
+			if tx.Error != nil {
+				return nil, tx.Error
+			}
+			var e error
+			if inherited, e = listEffectiveResCodes(req.RoleNo); e != nil {
+				return nil, e
+			}
+
+			// the role and the bindings are deleted together, so that they can be restored together
+			delNo := genDelNo()
+			return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+				if t := tx.Exec(`update role set is_del = 1, del_no = ? where role_no = ? and is_del = 0`, delNo, req.RoleNo); t.Error != nil {
+					return t.Error
+				}
+				if t := tx.Exec(`update role_resource set is_del = 1, del_no = ? where role_no = ? and is_del = 0`, delNo, req.RoleNo); t.Error != nil {
+					return t.Error
+				}
+				if t := tx.Exec(`update role_deny set is_del = 1, del_no = ? where role_no = ? and is_del = 0`, delNo, req.RoleNo); t.Error != nil {
+					return t.Error
+				}
+				return tx.Exec(`update role_parent set is_del = 1, del_no = ? where (role_no = ? or parent_role_no = ?) and is_del = 0`,
+					delNo, req.RoleNo, req.RoleNo).Error
+			})
+		})
+	})
+	if e != nil {
+		return e
+	}
+	ec.Infof("Deleted role %s", req.RoleNo)
+
+	if e := roleInfoCache.Del(ec, req.RoleNo); e != nil {
+		return e
+	}
+	if e := roleDenyCache.Del(ec, req.RoleNo); e != nil {
+		return e
+	}
+	for _, resCode := range inherited {
+		if e := roleResCache.Del(ec, roleResCacheKey(req.RoleNo, resCode)); e != nil {
+			return e
+		}
+	}
+	publishRoleChange(ec, req.RoleNo)
+
+	// deny rules and resources inherited from the role are no longer effective for the descendants
+	for _, child := range children {
+		if e := evictDenyOfRoleTree(ec, child); e != nil {
+			return e
+		}
+		if e := refreshResOfRoleTree(ec, child, inherited); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func RemoveResFromRole(ec miso.Rail, req RemoveRoleResReq) error {
+	_, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) {
+		tx := miso.GetMySQL().Exec(`update role_resource set is_del = 1 where role_no = ? and res_code = ? and is_del = 0`, req.RoleNo, req.ResCode)
+		return nil, tx.Error
+	})
+
+	if e == nil {
+		// the resource may still be inherited from other roles
+		e = refreshResOfRoleTree(ec, req.RoleNo, []string{req.ResCode})
+	}
+
+	return e
+}
+
+func AddResToRoleIfNotExist(ec miso.Rail, req AddRoleResReq, user common.User) error {
+
+	res, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for role
+		return lockResourceGlobal(ec, func() (any, error) {
+			// check if resource exist
+			missing, e := findMissingResCodes([]string{req.ResCode})
+			if e != nil {
+				return false, e
+			}
+			if len(missing) > 0 {
+				return false, miso.NewErrCode(ErrCodeResourceNotFound, "Resource not found")
+			}
+
+			var expiresAt *time.Time
+			if req.ExpiresAt != nil {
+				t := time.Time(*req.ExpiresAt)
+				if !t.After(time.Now()) {
+					return false, miso.NewErrCode(ErrCodeIllegalArgument, "Expiry time must be in the future")
+				}
+				expiresAt = &t
+			}
+
+			// check if role-resource relation exists
+			var prev ERoleRes
+			tx := miso.GetMySQL().Raw(`select id, expires_at from role_resource where role_no = ? and res_code = ? and is_del = 0`, req.RoleNo, req.ResCode).Scan(&prev)
+			if tx.Error != nil {
+				return false, tx.Error
+			}
+			if prev.Id > 0 { // relation exists already, the expiry may be changed
+				if sameExpiry(prev.ExpiresAt, expiresAt) {
+					return false, nil
+				}
+				if prev.ExpiresAt == nil {
+					return false, miso.NewErrCode(ErrCodeIllegalState, "Resource is already granted to the role permanently, remove it first to grant it temporarily")
+				}
+				return true, miso.GetMySQL().
+					Exec(`update role_resource set expires_at = ?, update_by = ? where id = ?`, expiresAt, user.Username, prev.Id).
+					Error
+			}
+
+			// create role-resource relation
+			rr := ERoleRes{
+				RoleNo:    req.RoleNo,
+				ResCode:   req.ResCode,
+				ExpiresAt: expiresAt,
+				CreateBy:  user.Username,
+				UpdateBy:  user.Username,
+			}
+
+			return true, miso.GetMySQL().
+				Table("role_resource").
+				Omit("Id", "CreateTime", "UpdateTime").
+				Create(&rr).Error
+		})
+	})
+
+	if e != nil {
+		return e
+	}
+
+	if isAdded := res.(bool); isAdded {
+		e = refreshResOfRoleTree(ec, req.RoleNo, nil)
+	}
+
+	return e
+}
+
+const (
+	maxRoleTagLen     = 32  // max length of a role tag
+	maxRoleTagsLen    = 255 // max length of the role tags joined
+	roleTagsSeparator = ","
+)
+
+// Trim and dedupe the tags, tags can't contain ','
+func normalizeRoleTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]struct{}{}
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if strings.Contains(t, roleTagsSeparator) {
+			return nil, miso.NewErrCode(ErrCodeIllegalArgument, fmt.Sprintf("Tag '%s' can't contain '%s'", t, roleTagsSeparator))
+		}
+		if len([]rune(t)) > maxRoleTagLen {
+			return nil, miso.NewErrCode(ErrCodeIllegalArgument, fmt.Sprintf("Tag '%s' is longer than %d characters", t, maxRoleTagLen))
+		}
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		normalized = append(normalized, t)
+	}
+	if len(strings.Join(normalized, roleTagsSeparator)) > maxRoleTagsLen {
+		return nil, miso.NewErrCode(ErrCodeIllegalArgument, "Too many tags")
+	}
+	return normalized, nil
+}
+
+// Trim the optional string, nil is returned if it's absent
+func trimOptionalStr(s *string, maxLen int, tooLongMsg string) (*string, error) {
+	if s == nil {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*s)
+	if len([]rune(v)) > maxLen {
+		return nil, miso.NewErrCode(ErrCodeIllegalArgument, tooLongMsg)
+	}
+	return &v, nil
+}
+
+func joinRoleTags(tags []string) (string, error) {
+	normalized, e := normalizeRoleTags(tags)
+	if e != nil {
+		return "", e
+	}
+	return strings.Join(normalized, roleTagsSeparator), nil
+}
+
+func splitRoleTags(tags string) []string {
+	split := []string{}
+	for _, t := range strings.Split(tags, roleTagsSeparator) {
+		if t = strings.TrimSpace(t); t != "" {
+			split = append(split, t)
+		}
+	}
+	return split
+}
+
+func toWRole(r ERole) WRole {
+	return WRole{
+		Id:         r.Id,
+		RoleNo:     r.RoleNo,
+		Name:       r.Name,
+		Desc:       r.Desc,
+		Owner:      r.Owner,
+		Contact:    r.Contact,
+		Tags:       splitRoleTags(r.Tags),
+		CreateTime: r.CreateTime,
+		CreateBy:   r.CreateBy,
+		UpdateTime: r.UpdateTime,
+		UpdateBy:   r.UpdateBy,
+	}
+}
+
+const (
+	bulkRoleResAdd     = "ADD"     // bind the resources to the role
+	bulkRoleResRemove  = "REMOVE"  // unbind the resources from the role
+	bulkRoleResReplace = "REPLACE" // the role will have exactly the resources
+)
+
+// Bind resources to role in bulk, resources already bound are ignored
+func BulkAddResToRole(ec miso.Rail, req BulkRoleResReq, user common.User) (BulkRoleResResp, error) {
+	return bulkUpdateRoleRes(ec, req, bulkRoleResAdd, user)
+}
+
+// Unbind resources from role in bulk, resources not bound are ignored
+func BulkRemoveResFromRole(ec miso.Rail, req BulkRoleResReq, user common.User) (BulkRoleResResp, error) {
+	return bulkUpdateRoleRes(ec, req, bulkRoleResRemove, user)
+}
+
+// Replace resources of role, the role will have exactly the resources requested
+func ReplaceResOfRole(ec miso.Rail, req BulkRoleResReq, user common.User) (BulkRoleResResp, error) {
+	return bulkUpdateRoleRes(ec, req, bulkRoleResReplace, user)
+}
+
+func bulkUpdateRoleRes(ec miso.Rail, req BulkRoleResReq, op string, user common.User) (BulkRoleResResp, error) {
+	resCodes := []string{}
+	for _, rc := range req.ResCodes {
+		if rc = strings.TrimSpace(rc); rc != "" {
+			resCodes = append(resCodes, rc)
+		}
+	}
+	if len(resCodes) < 1 && op != bulkRoleResReplace {
+		return BulkRoleResResp{}, miso.NewErrCode(ErrCodeIllegalArgument, "Resource codes are required")
+	}
+
+	res, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for role
+		return lockResourceGlobal(ec, func() (any, error) {
+			var id int
+			tx := miso.GetMySQL().Raw(`select id from role where role_no = ? and is_del = 0 limit 1`, req.RoleNo).Scan(&id)
+			if tx.Error != nil {
+				return nil, tx.Error
+			}
+			if id < 1 {
+				return nil, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+			}
+
+			var bound []string
+			tx = miso.GetMySQL().Raw(`select res_code from role_resource where role_no = ? and is_del = 0`, req.RoleNo).Scan(&bound)
+			if tx.Error != nil {
+				return nil, tx.Error
+			}
+
+			toAdd, toRemove := planRoleResChange(op, bound, resCodes)
+
+			// check if resources exist
+			missing, e := findMissingResCodes(toAdd)
+			if e != nil {
+				return nil, e
+			}
+			if len(missing) > 0 {
+				return nil, miso.NewErrCode(ErrCodeResourceNotFound, fmt.Sprintf("Resources not found: %v", missing))
+			}
+
+			e = miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+				if len(toRemove) > 0 {
+					if t := tx.Exec(`update role_resource set is_del = 1 where role_no = ? and res_code in ? and is_del = 0`, req.RoleNo, toRemove); t.Error != nil {
+						return t.Error
+					}
+				}
+				if len(toAdd) > 0 {
+					rrs := make([]ERoleRes, 0, len(toAdd))
+					for _, rc := range toAdd {
+						rrs = append(rrs, ERoleRes{RoleNo: req.RoleNo, ResCode: rc, CreateBy: user.Username, UpdateBy: user.Username})
+					}
+					return tx.Table("role_resource").Omit("Id", "CreateTime", "UpdateTime").Create(&rrs).Error
+				}
+				return nil
+			})
+			return BulkRoleResResp{Added: toAdd, Removed: toRemove}, e
+		})
+	})
+	if e != nil {
+		return BulkRoleResResp{}, e
+	}
+
+	resp := res.(BulkRoleResResp)
+	if len(resp.Added) > 0 || len(resp.Removed) > 0 {
+		ec.Infof("Updated resources of role %s, added: %v, removed: %v", req.RoleNo, resp.Added, resp.Removed)
+
+		// the resources removed may still be inherited from other roles
+		if e := refreshResOfRoleTree(ec, req.RoleNo, resp.Removed); e != nil {
+			return BulkRoleResResp{}, e
+		}
+	}
+	return resp, nil
+}
+
+// Find the codes that don't exist, a prefix grant (e.g., 'vfm.file.*') exists if any resource has the prefix
+func findMissingResCodes(resCodes []string) ([]string, error) {
+	missing := []string{}
+	codes := []string{}
+	for _, rc := range resCodes {
+		if !policy.IsResCodePrefixGrant(rc) {
+			codes = append(codes, rc)
+			continue
+		}
+		var id int
+		prefix := strings.TrimSuffix(rc, policy.ResCodeWildcardSuffix) + policy.ResCodeSeparator
+		tx := miso.GetMySQL().Raw(`select id from resource where left(code, ?) = ? and is_del = 0 limit 1`, len([]rune(prefix)), prefix).Scan(&id)
+		if tx.Error != nil {
+			return nil, tx.Error
+		}
+		if id < 1 {
+			missing = append(missing, rc)
+		}
+	}
+	if len(codes) < 1 {
+		return missing, nil
+	}
+
+	var existing []string
+	tx := miso.GetMySQL().Raw(`select code from resource where code in ? and is_del = 0`, codes).Scan(&existing)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	notFound, _, _ := diffStrings(codes, existing)
+	return append(missing, notFound...), nil
+}
+
+// Plan the resources to be bound and unbound, bound is the resources already bound to the role
+func planRoleResChange(op string, bound []string, resCodes []string) (toAdd []string, toRemove []string) {
+	toAdd, toRemove = []string{}, []string{}
+	notBound, _, alreadyBound := diffStrings(resCodes, bound)
+	switch op {
+	case bulkRoleResAdd:
+		toAdd = notBound
+	case bulkRoleResRemove:
+		toRemove = alreadyBound
+	case bulkRoleResReplace:
+		toAdd = notBound
+		_, toRemove, _ = diffStrings(resCodes, bound)
+	}
+	return toAdd, toRemove
+}
+
+// Split strings into the ones only in a, only in b and in both, duplicates are removed, order is preserved
+func diffStrings(a []string, b []string) (onlyA []string, onlyB []string, both []string) {
+	inA := map[string]struct{}{}
+	for _, v := range a {
+		inA[v] = struct{}{}
+	}
+	inB := map[string]struct{}{}
+	for _, v := range b {
+		inB[v] = struct{}{}
+	}
+
+	onlyA, onlyB, both = []string{}, []string{}, []string{}
+	seen := map[string]struct{}{}
+	for _, v := range a {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		if _, ok := inB[v]; ok {
+			both = append(both, v)
+		} else {
+			onlyA = append(onlyA, v)
+		}
+	}
+	for _, v := range b {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		onlyB = append(onlyB, v)
+	}
+	return onlyA, onlyB, both
+}
+
+func ListRoleRes(ec miso.Rail, req ListRoleResReq) (ListRoleResResp, error) {
+	var res []ListedRoleRes
+	tx := miso.GetMySQL().
+		Raw(`select rr.id, rr.res_code, rr.expires_at, rr.create_time, rr.create_by, r.name 'res_name' from role_resource rr
+			left join resource r on rr.res_code = r.code and r.is_del = 0
+			where rr.role_no = ? and rr.is_del = 0 order by rr.id desc limit ?, ?`, req.RoleNo, req.Paging.GetOffset(), req.Paging.GetLimit()).
+		Scan(&res)
+
+	if tx.Error != nil {
+		return ListRoleResResp{}, tx.Error
+	}
+
+	if res == nil {
+		res = []ListedRoleRes{}
+	}
+
+	var count int
+	tx = miso.GetMySQL().
+		Raw(`select count(*) from role_resource rr
+			left join resource r on rr.res_code = r.code and r.is_del = 0
+			where rr.role_no = ? and rr.is_del = 0`, req.RoleNo).
+		Scan(&count)
+
+	if tx.Error != nil {
+		return ListRoleResResp{}, tx.Error
+	}
+
+	return ListRoleResResp{Payload: res, Paging: miso.Paging{Limit: req.Paging.Limit, Page: req.Paging.Page, Total: count}}, nil
+}
+
+func AddRoleParent(ec miso.Rail, req AddRoleParentReq, user common.User) error {
+	req.RoleNo = strings.TrimSpace(req.RoleNo)
+	req.ParentRoleNo = strings.TrimSpace(req.ParentRoleNo)
+	if req.RoleNo == req.ParentRoleNo {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Role can't inherit from itself")
+	}
+	// administrator roles are granted all resources by bypassing the checks, which can't be inherited
+	if isAdminRole(req.ParentRoleNo) {
+		return miso.NewErrCode(ErrCodeIllegalState, "Role can't inherit from administrator role")
+	}
+
+	res, e := lockRoleParent(ec, func() (any, error) {
+		for _, roleNo := range []string{req.RoleNo, req.ParentRoleNo} {
+			var id int
+			tx := miso.GetMySQL().Raw(`select id from role where role_no = ? and is_del = 0 limit 1`, roleNo).Scan(&id)
+			if tx.Error != nil {
+				return false, tx.Error
+			}
+			if id < 1 {
+				return false, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+			}
+		}
+
+		var id int
+		tx := miso.GetMySQL().
+			Raw(`select id from role_parent where role_no = ? and parent_role_no = ? and is_del = 0 limit 1`, req.RoleNo, req.ParentRoleNo).
+			Scan(&id)
+		if tx.Error != nil {
+			return false, tx.Error
+		}
+		if id > 0 { // relation exists already
+			return false, nil
+		}
+
+		// the role must not be one of the ancestors of the parent role, or it will be a cycle
+		ancestors, e := listAncestorRoleNos(req.ParentRoleNo)
+		if e != nil {
+			return false, e
+		}
+		for _, a := range ancestors {
+			if a == req.RoleNo {
+				ec.Infof("Role '%s' is an ancestor of role '%s', can't inherit from it", req.RoleNo, req.ParentRoleNo)
+				return false, miso.NewErrCode(ErrCodeIllegalState, "Cyclic role inheritance is not allowed")
+			}
+		}
+
+		rp := ERoleParent{
+			RoleNo:       req.RoleNo,
+			ParentRoleNo: req.ParentRoleNo,
+			CreateBy:     user.Username,
+			UpdateBy:     user.Username,
+		}
+		return true, miso.GetMySQL().
+			Table("role_parent").
+			Omit("Id", "CreateTime", "UpdateTime").
+			Create(&rp).Error
+	})
+	if e != nil {
+		return e
+	}
+
+	if isAdded := res.(bool); isAdded {
+		if e := evictDenyOfRoleTree(ec, req.RoleNo); e != nil {
+			return e
+		}
+		return refreshResOfRoleTree(ec, req.RoleNo, nil)
+	}
+	return nil
+}
+
+func RemoveRoleParent(ec miso.Rail, req RemoveRoleParentReq) error {
+	req.RoleNo = strings.TrimSpace(req.RoleNo)
+	req.ParentRoleNo = strings.TrimSpace(req.ParentRoleNo)
+
+	_, e := lockRoleParent(ec, func() (any, error) {
+		tx := miso.GetMySQL().Exec(`update role_parent set is_del = 1 where role_no = ? and parent_role_no = ? and is_del = 0`, req.RoleNo, req.ParentRoleNo)
+		return nil, tx.Error
+	})
+	if e != nil {
+		return e
+	}
+
+	if e := evictDenyOfRoleTree(ec, req.RoleNo); e != nil {
+		return e
+	}
+
+	// resources that may no longer be inherited
+	inherited, e := listEffectiveResCodes(req.ParentRoleNo)
+	if e != nil {
+		return e
+	}
+	return refreshResOfRoleTree(ec, req.RoleNo, inherited)
+}
+
+func ListRoleParents(ec miso.Rail, req ListRoleParentReq) (ListRoleParentResp, error) {
+	var parentRoleNos []string
+	tx := miso.GetMySQL().Raw(`select parent_role_no from role_parent where role_no = ? and is_del = 0`, req.RoleNo).Scan(&parentRoleNos)
+	if tx.Error != nil {
+		return ListRoleParentResp{}, tx.Error
+	}
+
+	ancestorRoleNos, e := listAncestorRoleNos(req.RoleNo)
+	if e != nil {
+		return ListRoleParentResp{}, e
+	}
+
+	parents, e := listRoleBriefs(parentRoleNos)
+	if e != nil {
+		return ListRoleParentResp{}, e
+	}
+	ancestors, e := listRoleBriefs(ancestorRoleNos)
+	if e != nil {
+		return ListRoleParentResp{}, e
+	}
+	return ListRoleParentResp{Parents: parents, Ancestors: ancestors}, nil
+}
+
+func listRoleBriefs(roleNos []string) ([]RoleBrief, error) {
+	if len(roleNos) < 1 {
+		return []RoleBrief{}, nil
+	}
+
+	var roles []RoleBrief
+	tx := miso.GetMySQL().Raw("select role_no, name from role where role_no in ? and is_del = 0", roleNos).Scan(&roles)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if roles == nil {
+		roles = []RoleBrief{}
+	}
+	return roles, nil
+}
+
+// List all ancestors of the roles, the roles themselves are excluded
+func listAncestorRoleNos(roleNos ...string) ([]string, error) {
+	return walkRoleTree(roleNos, `select parent_role_no from role_parent where role_no in ? and is_del = 0`)
+}
+
+// List all descendants of the roles, the roles themselves are excluded
+func listDescendantRoleNos(roleNos ...string) ([]string, error) {
+	return walkRoleTree(roleNos, `select role_no from role_parent where parent_role_no in ? and is_del = 0`)
+}
+
+// Walk the role inheritance tree level by level, query selects the next level of role nos
+func walkRoleTree(roleNos []string, query string) ([]string, error) {
+	visited := map[string]struct{}{}
+	for _, r := range roleNos {
+		visited[r] = struct{}{}
+	}
+
+	found := []string{}
+	curr := roleNos
+	for len(curr) > 0 {
+		var next []string
+		if tx := miso.GetMySQL().Raw(query, curr).Scan(&next); tx.Error != nil {
+			return nil, tx.Error
+		}
+
+		curr = []string{}
+		for _, r := range next {
+			if _, ok := visited[r]; ok { // in case of cycle
+				continue
+			}
+			visited[r] = struct{}{}
+			found = append(found, r)
+			curr = append(curr, r)
+		}
+	}
+	return found, nil
+}
+
+func ListAllRoleBriefs(ec miso.Rail) ([]RoleBrief, error) {
+	var roles []RoleBrief
+	tx := miso.GetMySQL().Raw("select role_no, name from role where is_del = 0").Scan(&roles)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if roles == nil {
+		roles = []RoleBrief{}
+	}
+	return roles, nil
+}
+
+func ListRoles(ec miso.Rail, req ListRoleReq) (ListRoleResp, error) {
+	order, e := orderClause(req.SortBy, req.SortOrder, roleSortColumns)
+	if e != nil {
+		return ListRoleResp{}, e
+	}
+
+	applyCond := func(t *gorm.DB) *gorm.DB {
+		t = t.Where("is_del = 0")
+		if req.Name != "" {
+			t = t.Where("name LIKE ?", "%"+req.Name+"%")
+		}
+		if req.RoleNo != "" {
+			t = t.Where("role_no LIKE ?", "%"+req.RoleNo+"%")
+		}
+		if req.Owner != "" {
+			t = t.Where("owner = ?", req.Owner)
+		}
+		if tag := strings.TrimSpace(req.Tag); tag != "" {
+			t = t.Where("FIND_IN_SET(?, tags) > 0", tag)
+		}
+		return applyCreatedCond(t, req.CreateBy, req.CreateTimeFrom, req.CreateTimeTo)
+	}
+
+	var eroles []ERole
+	tx := miso.GetMySQL().
+		Table("role").
+		Order(order)
+
+	tx = applyCond(tx).
+		Offset(req.Paging.GetOffset()).
+		Limit(req.Paging.GetLimit()).
+		Scan(&eroles)
+	if tx.Error != nil {
+		return ListRoleResp{}, tx.Error
+	}
+
+	roles := make([]WRole, 0, len(eroles))
+	for _, r := range eroles {
+		roles = append(roles, toWRole(r))
+	}
+
+	var count int
+	tx = miso.GetMySQL().
+		Table("role").
+		Select("COUNT(*)")
+
+	tx = applyCond(tx).
+		Scan(&count)
+	if tx.Error != nil {
+		return ListRoleResp{}, tx.Error
+	}
+
+	return ListRoleResp{Payload: roles, Paging: miso.Paging{Limit: req.Paging.Limit, Page: req.Paging.Page, Total: count}}, nil
+}
+
+// Test access to resource, access is granted if any of the roles has access to the resources required
+func TestResourceAccess(ec miso.Rail, req TestResAccessReq) (TestResAccessResp, error) {
+	return testResourceAccess(ec, req, nil, nil)
+}
+
+// Test access to resources in batch, the lookups are shared among the items
+func BatchTestResourceAccess(ec miso.Rail, req BatchTestResAccessReq) (BatchTestResAccessResp, error) {
+	if len(req.Items) > maxBatchAccessTestItems {
+		return BatchTestResAccessResp{}, miso.NewErrCode(ErrCodeIllegalArgument, fmt.Sprintf("At most %d items can be tested in one batch", maxBatchAccessTestItems))
+	}
+
+	memo := newAccessMemo()
+	results := make([]ResAccessTestResult, 0, len(req.Items))
+	for _, it := range req.Items {
+		r, e := testResourceAccess(ec, TestResAccessReq{RoleNo: req.RoleNo, RoleNos: req.RoleNos, Url: it.Url, Method: it.Method}, nil, memo)
+		if e != nil {
+			return BatchTestResAccessResp{}, e
+		}
+		results = append(results, ResAccessTestResult{Url: it.Url, Method: it.Method, Valid: r.Valid})
+	}
+	return BatchTestResAccessResp{Items: results}, nil
+}
+
+// Test access to resource, each step is recorded in ex if ex is not nil, lookups are memoized in memo if memo is not nil.
+//
+// Decisions are audited unless they are being explained.
+func testResourceAccess(ec miso.Rail, req TestResAccessReq, ex *ExplainResAccessResp, memo *accessMemo) (TestResAccessResp, error) {
+	url := req.Url
+	roleNos := policy.ResolveRoleNos(req.RoleNo, req.RoleNos)
+
+	// some sanitization & standardization for the url
+	url = policy.PreprocessUrl(url)
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	if ex != nil {
+		ex.Url = url
+		ex.Method = method
+		ex.RoleNos = roleNos
+	}
+	ex.step("preprocess", TraceSrcNone, "url '%s' (%s) is normalized as '%s' (%s), roleNos: %v", req.Url, req.Method, url, method, roleNos)
+
+	var cur CachedUrlRes
+	decide := func(valid bool, reason string, args ...any) (TestResAccessResp, error) {
+		ex.reason(reason, args...)
+		if ex == nil {
+			auditDecision(ec, valid, func() AccessDecision {
+				return AccessDecision{
+					RoleNos:  strings.Join(roleNos, ","),
+					Method:   method,
+					Url:      url,
+					PathNo:   cur.PathNo,
+					ResCodes: strings.Join(cur.ResCodes, ","),
+					Reason:   fmt.Sprintf(reason, args...),
+				}
+			})
+		}
+		if valid {
+			return permitted, nil
+		}
+		return forbidden, nil
+	}
+
+	// find resource required for the url
+	cur, e := memo.lookupUrlRes(ec, url, method, ex)
+	if e != nil {
+		ec.Infof("Rejected '%s' (%s), path not found", url, method)
+		explainPathNotFound(ex, url, method)
+		return decide(false, "path not found")
+	}
+	ex.matched(cur)
+
+	// public path type, doesn't require access to resource
+	if cur.Ptype == PtPublic {
+		return decide(true, "path '%s' is public", cur.PathNo)
+	}
+
+	// doesn't even have role
+	if len(roleNos) < 1 {
+		ec.Infof("Rejected '%s', user doesn't have roleNo", url)
+		return decide(false, "user doesn't have roleNo")
+	}
+
+	var ok bool
+
+	// the requiredRes resources no
+	requiredRes := cur.ResCodes
+	if len(requiredRes) < 1 {
+		ec.Infof("Rejected '%s', path doesn't have any resource bound yet", url)
+		return decide(false, "path '%s' doesn't have any resource bound yet", cur.PathNo)
+	}
+
+	// deny rules are evaluated before the grants
+	deny, e := memo.loadRolesDeny(ec, roleNos, ex)
+	if e != nil {
+		return forbidden, e
+	}
+	if deny.deniesPath(cur.PathNo) {
+		ec.Infof("Rejected '%s', roleNos: %v, path '%s' is denied", url, roleNos, cur.PathNo)
+		return decide(false, "path '%s' is denied", cur.PathNo)
+	}
+	if ex != nil {
+		for _, resCode := range requiredRes {
+			if _, denied := deny.resCodes[resCode]; denied {
+				ex.grant(ResGrant{ResCode: resCode, Denied: true})
+			}
+		}
+	}
+	if requiredRes, ok = deny.filterRes(requiredRes, cur.ResMode); !ok {
+		ec.Infof("Rejected '%s', roleNos: %v, required resources %v (%s) are denied", url, roleNos, cur.ResCodes, cur.ResMode)
+		return decide(false, "required resources %v (%s) are denied", cur.ResCodes, cur.ResMode)
+	}
+
+	ok, e = checkRolesResMode(ec, roleNos, requiredRes, cur.ResMode, ex, memo)
+	if e != nil {
+		return forbidden, e
+	}
+
+	// the role doesn't have access to the required resource
+	if !ok {
+		ec.Infof("Rejected '%s', roleNos: %v, roles don't have access to required resources %v (%s)", url, roleNos, requiredRes, cur.ResMode)
+		return decide(false, "roles don't have access to required resources %v (%s)", requiredRes, cur.ResMode)
+	}
+
+	return decide(true, "roles have access to required resources %v (%s)", requiredRes, cur.ResMode)
+}
+
+type memoUrlRes struct {
+	cur CachedUrlRes
+	err error
+}
+
+// Memoized lookups shared by access tests of the same roles, methods are also valid for nil *accessMemo
+type accessMemo struct {
+	urlRes  map[string]memoUrlRes // method:url -> CachedUrlRes
+	roleRes map[string]bool       // role:res -> flag
+	deny    *rolesDeny            // deny rules of the roles
+}
+
+func newAccessMemo() *accessMemo {
+	return &accessMemo{urlRes: map[string]memoUrlRes{}, roleRes: map[string]bool{}}
+}
+
+func (m *accessMemo) lookupUrlRes(ec miso.Rail, url string, method string, ex *ExplainResAccessResp) (CachedUrlRes, error) {
+	if m == nil {
+		return lookupUrlRes(ec, url, method, ex)
+	}
+
+	k := method + ":" + url
+	if v, ok := m.urlRes[k]; ok {
+		return v.cur, v.err
+	}
+	cur, e := lookupUrlRes(ec, url, method, ex)
+	m.urlRes[k] = memoUrlRes{cur: cur, err: e}
+	return cur, e
+}
+
+func (m *accessMemo) loadRolesDeny(rail miso.Rail, roleNos []string, ex *ExplainResAccessResp) (rolesDeny, error) {
+	if m == nil {
+		return loadRolesDeny(rail, roleNos, ex)
+	}
+
+	if m.deny != nil {
+		return *m.deny, nil
+	}
+	d, e := loadRolesDeny(rail, roleNos, ex)
+	if e != nil {
+		return d, e
+	}
+	m.deny = &d
+	return d, nil
+}
 
-func ListAllRoleBriefs(ec miso.Rail) ([]RoleBrief, error) {
-	var roles []RoleBrief
-	tx := miso.GetMySQL().Raw("select role_no, name from role").Scan(&roles)
-	if tx.Error != nil {
-		return nil, tx.Error
+func (m *accessMemo) checkRoleRes(rail miso.Rail, roleNo string, resCode string) (bool, error) {
+	if m == nil {
+		return checkRoleRes(rail, roleNo, resCode)
 	}
-	if roles == nil {
-		roles = []RoleBrief{}
+
+	k := roleResCacheKey(roleNo, resCode)
+	if ok, found := m.roleRes[k]; found {
+		return ok, nil
 	}
-	return roles, nil
+	ok, e := checkRoleRes(rail, roleNo, resCode)
+	if e != nil {
+		return false, e
+	}
+	m.roleRes[k] = ok
+	return ok, nil
 }
 
-func ListRoles(ec miso.Rail, req ListRoleReq) (ListRoleResp, error) {
-	var roles []WRole
-	tx := miso.GetMySQL().
-		Raw("select * from role order by id desc limit ?, ?", req.Paging.GetOffset(), req.Paging.GetLimit()).
-		Scan(&roles)
-	if tx.Error != nil {
-		return ListRoleResp{}, tx.Error
+// Deny rules merged from multiple roles
+type rolesDeny struct {
+	resCodes map[string]struct{}
+	pathNos  map[string]struct{}
+}
+
+func (d rolesDeny) deniesPath(pathNo string) bool {
+	_, ok := d.pathNos[pathNo]
+	return ok
+}
+
+// Filter out the denied resources, returns false if the remaining resources are no longer sufficient
+func (d rolesDeny) filterRes(resCodes []string, mode PathResMode) ([]string, bool) {
+	return policy.FilterDeniedRes(resCodes, mode, func(resCode string) bool {
+		_, ok := d.resCodes[resCode]
+		return ok
+	})
+}
+
+// Load deny rules of the roles, a resource or path denied by any of the roles is denied
+func loadRolesDeny(rail miso.Rail, roleNos []string, ex *ExplainResAccessResp) (rolesDeny, error) {
+	d := rolesDeny{resCodes: map[string]struct{}{}, pathNos: map[string]struct{}{}}
+	for _, roleNo := range roleNos {
+		src := ex.source(rail, func() (bool, error) { return roleDenyCache.Exists(rail, roleNo) })
+		crd, e := roleDenyCache.Get(rail, roleNo, func() (CachedRoleDeny, error) {
+			return listEffectiveDeny(roleNo)
+		})
+		if e != nil {
+			return d, e
+		}
+		ex.step("load deny rules", src, "role '%s' denies resources: %v, paths: %v", roleNo, crd.ResCodes, crd.PathNos)
+		for _, resCode := range crd.ResCodes {
+			d.resCodes[resCode] = struct{}{}
+		}
+		for _, pathNo := range crd.PathNos {
+			d.pathNos[pathNo] = struct{}{}
+		}
 	}
-	if roles == nil {
-		roles = []WRole{}
+	return d, nil
+}
+
+// List deny rules of the role, including the ones inherited from its ancestors
+func listEffectiveDeny(roleNo string) (CachedRoleDeny, error) {
+	ancestors, e := listAncestorRoleNos(roleNo)
+	if e != nil {
+		return CachedRoleDeny{}, e
 	}
 
-	var count int
-	tx = miso.GetMySQL().Raw("select count(*) from role").Scan(&count)
-	if tx.Error != nil {
-		return ListRoleResp{}, tx.Error
+	var denies []ERoleDeny
+	t := miso.GetMySQL().
+		Raw("select res_code, path_no from role_deny where role_no in ? and is_del = 0", append([]string{roleNo}, ancestors...)).
+		Scan(&denies)
+	if t.Error != nil {
+		return CachedRoleDeny{}, t.Error
 	}
 
-	return ListRoleResp{Payload: roles, Paging: miso.Paging{Limit: req.Paging.Limit, Page: req.Paging.Page, Total: count}}, nil
+	crd := CachedRoleDeny{ResCodes: []string{}, PathNos: []string{}}
+	for _, d := range denies {
+		if d.ResCode != "" {
+			crd.ResCodes = append(crd.ResCodes, d.ResCode)
+		}
+		if d.PathNo != "" {
+			crd.PathNos = append(crd.PathNos, d.PathNo)
+		}
+	}
+	return crd, nil
 }
 
-// Test access to resource
-func TestResourceAccess(ec miso.Rail, req TestResAccessReq) (TestResAccessResp, error) {
-	url := req.Url
-	roleNo := req.RoleNo
+// Evict cached deny rules of the role and its descendants
+func evictDenyOfRoleTree(ec miso.Rail, roleNo string) error {
+	descendants, e := listDescendantRoleNos(roleNo)
+	if e != nil {
+		return e
+	}
+	roleNos := append([]string{roleNo}, descendants...)
+	for _, r := range roleNos {
+		if e := roleDenyCache.Del(ec, r); e != nil {
+			return e
+		}
+	}
+	publishRoleChange(ec, roleNos...)
+	return nil
+}
 
-	// some sanitization & standardization for the url
-	url = preprocessUrl(url)
-	method := strings.ToUpper(strings.TrimSpace(req.Method))
+func AddRoleDeny(ec miso.Rail, req AddRoleDenyReq, user common.User) error {
+	req.ResCode = strings.TrimSpace(req.ResCode)
+	req.PathNo = strings.TrimSpace(req.PathNo)
+	if (req.ResCode == "") == (req.PathNo == "") {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Either resource code or path no is required")
+	}
 
-	// find resource required for the url
-	cur, e := lookupUrlRes(ec, url, method)
+	res, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) { // lock for role
+		var id int
+		tx := miso.GetMySQL().Raw(`select id from role where role_no = ? and is_del = 0 limit 1`, req.RoleNo).Scan(&id)
+		if tx.Error != nil {
+			return false, tx.Error
+		}
+		if id < 1 {
+			return false, miso.NewErrCode(ErrCodeRoleNotFound, "Role not found")
+		}
+
+		// check if the resource or path exist
+		if req.ResCode != "" {
+			tx = miso.GetMySQL().Raw(`select id from resource where code = ? and is_del = 0 limit 1`, req.ResCode).Scan(&id)
+		} else {
+			tx = miso.GetMySQL().Raw(`select id from path where path_no = ? and is_del = 0 limit 1`, req.PathNo).Scan(&id)
+		}
+		if tx.Error != nil {
+			return false, tx.Error
+		}
+		if id < 1 {
+			if req.ResCode != "" {
+				return false, miso.NewErrCode(ErrCodeResourceNotFound, "Resource not found")
+			}
+			return false, miso.NewErrCode(ErrCodePathNotFound, "Path not found")
+		}
+
+		// check if the deny rule exists
+		var rdid int
+		tx = miso.GetMySQL().
+			Raw(`select id from role_deny where role_no = ? and res_code = ? and path_no = ? and is_del = 0 limit 1`, req.RoleNo, req.ResCode, req.PathNo).
+			Scan(&rdid)
+		if tx.Error != nil {
+			return false, tx.Error
+		}
+		if rdid > 0 {
+			return false, nil
+		}
+
+		rd := ERoleDeny{
+			RoleNo:   req.RoleNo,
+			ResCode:  req.ResCode,
+			PathNo:   req.PathNo,
+			CreateBy: user.Username,
+			UpdateBy: user.Username,
+		}
+		return true, miso.GetMySQL().
+			Table("role_deny").
+			Omit("Id", "CreateTime", "UpdateTime").
+			Create(&rd).Error
+	})
 	if e != nil {
-		ec.Infof("Rejected '%s' (%s), path not found", url, method)
-		return forbidden, nil
+		return e
 	}
 
-	// public path type, doesn't require access to resource
-	if cur.Ptype == PtPublic {
-		return permitted, nil
+	if isAdded := res.(bool); isAdded {
+		return evictDenyOfRoleTree(ec, req.RoleNo)
 	}
+	return nil
+}
 
-	// doesn't even have role
-	roleNo = strings.TrimSpace(roleNo)
-	if roleNo == "" {
-		ec.Infof("Rejected '%s', user doesn't have roleNo", url)
-		return forbidden, nil
+func RemoveRoleDeny(ec miso.Rail, req RemoveRoleDenyReq) error {
+	req.ResCode = strings.TrimSpace(req.ResCode)
+	req.PathNo = strings.TrimSpace(req.PathNo)
+	if (req.ResCode == "") == (req.PathNo == "") {
+		return miso.NewErrCode(ErrCodeIllegalArgument, "Either resource code or path no is required")
 	}
 
-	// the requiredRes resources no
-	requiredRes := cur.ResCode
-	if requiredRes == "" {
-		ec.Infof("Rejected '%s', path doesn't have any resource bound yet", url)
-		return forbidden, nil
+	_, e := miso.RLockRun(ec, "goauth:role:"+req.RoleNo, func() (any, error) {
+		tx := miso.GetMySQL().
+			Exec(`update role_deny set is_del = 1 where role_no = ? and res_code = ? and path_no = ? and is_del = 0`, req.RoleNo, req.ResCode, req.PathNo)
+		return nil, tx.Error
+	})
+	if e != nil {
+		return e
 	}
+	return evictDenyOfRoleTree(ec, req.RoleNo)
+}
 
-	ok, e := checkRoleRes(ec, roleNo, requiredRes)
-	if e != nil {
-		return forbidden, e
+func ListRoleDenies(ec miso.Rail, req ListRoleDenyReq) (ListRoleDenyResp, error) {
+	var res []ListedRoleDeny
+	tx := miso.GetMySQL().
+		Raw(`select rd.id, rd.res_code, r.name 'res_name', rd.path_no, p.method, p.url, rd.create_time, rd.create_by from role_deny rd
+			left join resource r on rd.res_code != '' and rd.res_code = r.code and r.is_del = 0
+			left join path p on rd.path_no != '' and rd.path_no = p.path_no and p.is_del = 0
+			where rd.role_no = ? and rd.is_del = 0 order by rd.id desc limit ?, ?`, req.RoleNo, req.Paging.GetOffset(), req.Paging.GetLimit()).
+		Scan(&res)
+	if tx.Error != nil {
+		return ListRoleDenyResp{}, tx.Error
+	}
+	if res == nil {
+		res = []ListedRoleDeny{}
 	}
 
-	// the role doesn't have access to the required resource
-	if !ok {
-		ec.Infof("Rejected '%s', roleNo: '%s', role doesn't have access to required resource '%s'", url, roleNo, requiredRes)
-		return forbidden, nil
+	var count int
+	tx = miso.GetMySQL().Raw(`select count(*) from role_deny where role_no = ? and is_del = 0`, req.RoleNo).Scan(&count)
+	if tx.Error != nil {
+		return ListRoleDenyResp{}, tx.Error
 	}
 
-	return permitted, nil
+	return ListRoleDenyResp{Payload: res, Paging: miso.RespPage(req.Paging, count)}, nil
+}
+
+// Check whether the roles have access to the resources, with PrmAll, all of the resources are required, otherwise any of them is sufficient.
+//
+// A resource is accessible if any of the roles has access to it.
+func checkRolesResMode(rail miso.Rail, roleNos []string, resCodes []string, mode PathResMode, ex *ExplainResAccessResp,
+	memo *accessMemo) (bool, error) {
+	return policy.CheckResMode(resCodes, mode, func(resCode string) (bool, error) {
+		return checkRolesRes(rail, roleNos, resCode, ex, memo)
+	})
+}
+
+// Check whether any of the roles has access to the resource
+func checkRolesRes(rail miso.Rail, roleNos []string, resCode string, ex *ExplainResAccessResp, memo *accessMemo) (bool, error) {
+	for _, roleNo := range roleNos {
+		ok, e := memo.checkRoleRes(rail, roleNo, resCode)
+		if e != nil {
+			return false, e
+		}
+		if ok {
+			if isAdminRole(roleNo) {
+				// explaining the decision is not a real bypass
+				if ex == nil {
+					markAdminBypass(rail, roleNo, resCode)
+				}
+				ex.step("check resource", TraceSrcNone, "role '%s' is the administrator, resource '%s' is granted", roleNo, resCode)
+			} else {
+				ex.step("check resource", TraceSrcCache, "role '%s' has access to resource '%s'", roleNo, resCode)
+			}
+			ex.grant(ResGrant{ResCode: resCode, RoleNo: roleNo})
+			return true, nil
+		}
+	}
+	ex.step("check resource", TraceSrcCache, "none of the roles has access to resource '%s'", resCode)
+	ex.grant(ResGrant{ResCode: resCode})
+	return false, nil
 }
 
+// Check whether the role has access to the resource, administrator roles always have access, the bypass is recorded
+// by the caller
 func checkRoleRes(rail miso.Rail, roleNo string, resCode string) (bool, error) {
-	if roleNo == DefaultAdminRoleNo {
+	if isAdminRole(roleNo) {
 		return true, nil
 	}
 
-	ok, e := roleResCache.Exists(rail, fmt.Sprintf("role:%s:res:%s", roleNo, resCode))
+	// the resource may be granted directly or by prefix grants, e.g., 'vfm.file.*'
+	now := time.Now()
+	for _, code := range append([]string{resCode}, policy.ResCodePrefixGrants(resCode)...) {
+		v, ok, e := getCachedRoleRes(rail, roleNo, code)
+		if e != nil {
+			return false, e
+		}
+		if ok && !roleResExpired(v, now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Get the grant cached in roleResCache, ok is false if the resource is not granted
+func getCachedRoleRes(rail miso.Rail, roleNo string, resCode string) (v string, ok bool, err error) {
+	key := roleResCacheKey(roleNo, resCode)
+	ok, err = roleResCache.Exists(rail, key)
+	if err != nil || !ok {
+		return "", false, err
+	}
+	v, err = roleResCache.Get(rail, key, nil)
+	if err != nil {
+		// the key may be evicted in between
+		if ok, e := roleResCache.Exists(rail, key); e == nil && !ok {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+// Value of roleResCache, it's either '1' (never expires) or the expiry time in unix milliseconds
+func roleResCacheVal(expiresAt *time.Time) string {
+	if expiresAt == nil {
+		return "1"
+	}
+	return strconv.FormatInt(expiresAt.UnixMilli(), 10)
+}
+
+// Check whether the grant cached in roleResCache is expired
+func roleResExpired(v string, now time.Time) bool {
+	if v == "1" {
+		return false
+	}
+	ms, e := strconv.ParseInt(v, 10, 64)
 	if e != nil {
-		return false, e
+		return false
 	}
-	return ok, nil
+	return ms <= now.UnixMilli()
+}
+
+func sameExpiry(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// Remove the expired grants, and refresh the cache of the roles affected
+func PurgeExpiredRoleRes(rail miso.Rail) error {
+	// the same time is used to select and delete, so that the grants deleted are exactly the ones refreshed
+	now := time.Now()
+	var expired []ERoleRes
+	tx := miso.GetMySQL().Raw(`select role_no, res_code from role_resource where expires_at <= ? and is_del = 0`, now).Scan(&expired)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	byRole := map[string][]string{}
+	for _, rr := range expired {
+		byRole[rr.RoleNo] = append(byRole[rr.RoleNo], rr.ResCode)
+	}
+
+	for roleNo, resCodes := range byRole {
+		_, e := miso.RLockRun(rail, "goauth:role:"+roleNo, func() (any, error) { // lock for role
+			return nil, miso.GetMySQL().
+				Exec(`update role_resource set is_del = 1 where role_no = ? and expires_at <= ? and is_del = 0`, roleNo, now).
+				Error
+		})
+		if e != nil {
+			return e
+		}
+		rail.Infof("Purged expired resources of role %s: %v", roleNo, resCodes)
+
+		// the resources may still be inherited from other roles
+		if e := refreshResOfRoleTree(rail, roleNo, resCodes); e != nil {
+			return e
+		}
+	}
+	return nil
 }
 
 // Load cache for role -> resources
@@ -922,20 +2726,59 @@ func LoadRoleResCache(ec miso.Rail) error {
 }
 
 func _loadResOfRole(ec miso.Rail, roleNo string) error {
-	roleResList, e := listRoleRes(ec, roleNo)
+	grants, e := listEffectiveResGrants(roleNo)
+	if e != nil {
+		return e
+	}
+
+	for _, g := range grants {
+		roleResCache.Put(ec, roleResCacheKey(roleNo, g.ResCode), roleResCacheVal(g.ExpiresAt))
+	}
+	return nil
+}
+
+// Reload cache of resources of the role and its descendants, evictCandidates that are no longer accessible are evicted
+func refreshResOfRoleTree(ec miso.Rail, roleNo string, evictCandidates []string) error {
+	descendants, e := listDescendantRoleNos(roleNo)
 	if e != nil {
 		return e
 	}
 
-	for _, rr := range roleResList {
-		roleResCache.Put(ec, fmt.Sprintf("role:%s:res:%s", rr.RoleNo, rr.ResCode), "1")
+	roleNos := append([]string{roleNo}, descendants...)
+	for _, r := range roleNos {
+		grants, e := listEffectiveResGrants(r)
+		if e != nil {
+			return e
+		}
+
+		accessible := map[string]struct{}{}
+		for _, g := range grants {
+			accessible[g.ResCode] = struct{}{}
+			if e := roleResCache.Put(ec, roleResCacheKey(r, g.ResCode), roleResCacheVal(g.ExpiresAt)); e != nil {
+				return e
+			}
+		}
+
+		for _, resCode := range evictCandidates {
+			if _, ok := accessible[resCode]; ok {
+				continue
+			}
+			if e := roleResCache.Del(ec, roleResCacheKey(r, resCode)); e != nil {
+				return e
+			}
+		}
 	}
+	publishRoleChange(ec, roleNos...)
 	return nil
 }
 
+func roleResCacheKey(roleNo string, resCode string) string {
+	return fmt.Sprintf("role:%s:res:%s", roleNo, resCode)
+}
+
 func listRoleNos(ec miso.Rail) ([]string, error) {
 	var ern []string
-	t := miso.GetMySQL().Raw("select role_no from role").Scan(&ern)
+	t := miso.GetMySQL().Raw("select role_no from role where is_del = 0").Scan(&ern)
 	if t.Error != nil {
 		return nil, t.Error
 	}
@@ -946,34 +2789,176 @@ func listRoleNos(ec miso.Rail) ([]string, error) {
 	return ern, nil
 }
 
-func listRoleRes(ec miso.Rail, roleNo string) ([]ERoleRes, error) {
-	var rr []ERoleRes
-	t := miso.GetMySQL().Raw("select * from role_resource where role_no = ?", roleNo).Scan(&rr)
+// List codes of resources that are accessible by the role, including the ones inherited from its ancestors
+func listEffectiveResCodes(roleNo string) ([]string, error) {
+	grants, e := listEffectiveResGrants(roleNo)
+	if e != nil {
+		return nil, e
+	}
+
+	codes := make([]string, 0, len(grants))
+	for _, g := range grants {
+		codes = append(codes, g.ResCode)
+	}
+	return codes, nil
+}
+
+type resGrantExpiry struct {
+	ResCode   string
+	ExpiresAt *time.Time // nil if any of the grants never expires
+}
+
+// List resources that are accessible by the role with the expiry, including the ones inherited from its ancestors, expired grants are excluded
+func listEffectiveResGrants(roleNo string) ([]resGrantExpiry, error) {
+	ancestors, e := listAncestorRoleNos(roleNo)
+	if e != nil {
+		return nil, e
+	}
+
+	// count(expires_at) excludes null, i.e., the grants that never expire
+	var grants []resGrantExpiry
+	t := miso.GetMySQL().
+		Raw(`select res_code, if(count(expires_at) < count(*), null, max(expires_at)) expires_at from role_resource
+			where role_no in ? and is_del = 0 and (expires_at is null or expires_at > ?) group by res_code`,
+			append([]string{roleNo}, ancestors...), time.Now()).
+		Scan(&grants)
 	if t.Error != nil {
 		if errors.Is(t.Error, gorm.ErrRecordNotFound) {
-			return []ERoleRes{}, nil
+			return []resGrantExpiry{}, nil
 		}
 		return nil, t.Error
 	}
 
-	return rr, nil
+	if grants == nil {
+		grants = []resGrantExpiry{}
+	}
+	return grants, nil
 }
 
-func lookupUrlRes(ec miso.Rail, url string, method string) (CachedUrlRes, error) {
+func lookupUrlRes(ec miso.Rail, url string, method string, ex *ExplainResAccessResp) (CachedUrlRes, error) {
 	cur, e := urlResCache.Get(ec, method+":"+url, nil)
+	if e == nil {
+		ex.step("lookup path", TraceSrcCache, "path '%s' is found by exact url", cur.PathNo)
+		return cur, nil
+	}
+	ex.step("lookup path", TraceSrcCache, "path is not found by exact url")
+
+	// url may match one of the url patterns, e.g., '/file/{fileId}'
+	pattern, ok, pe := matchUrlPattern(ec, url, method, ex)
+	if pe != nil {
+		ec.Errorf("Failed to match url patterns, url: '%s' (%s), %v", url, method, pe)
+		return CachedUrlRes{}, e
+	}
+	if !ok {
+		return CachedUrlRes{}, e
+	}
+
+	cur, e = urlResCache.Get(ec, method+":"+pattern, nil)
 	if e != nil {
+		ex.step("lookup path", TraceSrcCache, "path of url pattern '%s' is not found", pattern)
 		return CachedUrlRes{}, e
 	}
+	ex.step("lookup path", TraceSrcCache, "path '%s' is found by url pattern '%s'", cur.PathNo, pattern)
 	return cur, nil
 }
 
+// Match url against the url patterns of the http method, returns the most specific pattern that matches the url
+func matchUrlPattern(ec miso.Rail, url string, method string, ex *ExplainResAccessResp) (string, bool, error) {
+	src := ex.source(ec, func() (bool, error) { return urlPatternCache.Exists(ec, method) })
+	patterns, e := urlPatternCache.Get(ec, method, func() ([]string, error) {
+		return listUrlPatterns(method)
+	})
+	if e != nil {
+		return "", false, e
+	}
+	if len(patterns) < 1 {
+		ex.step("match url pattern", src, "no url pattern for method '%s'", method)
+		return "", false, nil
+	}
+
+	pattern, ok := getUrlPatternTrie(method, patterns).Match(url)
+	if ok {
+		ex.step("match url pattern", src, "url matches pattern '%s' among %d patterns", pattern, len(patterns))
+	} else {
+		ex.step("match url pattern", src, "url doesn't match any of the %d patterns", len(patterns))
+	}
+	return pattern, ok, nil
+}
+
+// Trie of url patterns built in memory, it's rebuilt when the patterns cached in urlPatternCache change
+type urlPatternTrie struct {
+	patterns []string
+	trie     *policy.PathTrie
+}
+
+var (
+	urlPatternTrieMu sync.RWMutex
+	urlPatternTries  = map[string]urlPatternTrie{} // method -> trie of url patterns
+)
+
+func getUrlPatternTrie(method string, patterns []string) *policy.PathTrie {
+	urlPatternTrieMu.RLock()
+	cached, ok := urlPatternTries[method]
+	urlPatternTrieMu.RUnlock()
+	if ok && equalStrs(cached.patterns, patterns) {
+		return cached.trie
+	}
+
+	t := policy.NewPathTrie(patterns)
+	urlPatternTrieMu.Lock()
+	urlPatternTries[method] = urlPatternTrie{patterns: patterns, trie: t}
+	urlPatternTrieMu.Unlock()
+	return t
+}
+
+func equalStrs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func listUrlPatterns(method string) ([]string, error) {
+	var urls []string
+	tx := miso.GetMySQL().
+		Raw("select url from path where method = ? and is_del = 0 and (url like '%{%' or url like '%*%')", method).
+		Scan(&urls)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	patterns := []string{}
+	for _, u := range urls {
+		u = policy.PreprocessUrl(u)
+		if policy.IsUrlPattern(u) {
+			patterns = append(patterns, u)
+		}
+	}
+	return patterns, nil
+}
+
+func evictUrlPatternCache(ec miso.Rail, method string) {
+	urlPatternTrieMu.Lock()
+	delete(urlPatternTries, method)
+	urlPatternTrieMu.Unlock()
+
+	if e := urlPatternCache.Del(ec, method); e != nil {
+		ec.Errorf("Failed to evict urlPatternCache, method: %s, %v", method, e)
+	}
+}
+
 // Load cache for path -> resource
 func LoadPathResCache(rail miso.Rail) error {
 
 	_, e := miso.RLockRun(rail, "goauth:path:res:cache", func() (any, error) {
 		var paths []ExtendedPathRes
 		tx := miso.GetMySQL().
-			Raw("select p.*, pr.res_code from path p left join path_resource pr on p.path_no = pr.path_no").
+			Raw("select p.*, pr.res_code from path p left join path_resource pr on p.path_no = pr.path_no and pr.is_del = 0 where p.is_del = 0").
 			Scan(&paths)
 		if tx.Error != nil {
 			return nil, tx.Error
@@ -982,81 +2967,79 @@ func LoadPathResCache(rail miso.Rail) error {
 			return nil, nil
 		}
 
-		for _, ep := range paths {
-			ep.Url = preprocessUrl(ep.Url)
-			if e := urlResCache.Put(rail, ep.Method+":"+ep.Url, toCachedUrlRes(ep)); e != nil {
+		patterns := map[string][]string{} // method -> url patterns
+		for _, cur := range toCachedUrlRes(paths) {
+			if e := urlResCache.Put(rail, cur.Method+":"+cur.Url, cur); e != nil {
 				return nil, fmt.Errorf("failed to store urlResCache, %w", e)
 			}
 
-			pathNo := genPathNo(ep.Pgroup, ep.Url, ep.Method)
+			if policy.IsUrlPattern(cur.Url) {
+				patterns[cur.Method] = append(patterns[cur.Method], cur.Url)
+			}
+
+			pathNo := genPathNo(cur.Pgroup, cur.Url, cur.Method)
 			if err := pathNoCache.Put(rail, pathNo, ""); err != nil {
 				return nil, fmt.Errorf("failed to store pathNoCache, %w", err)
 			}
 		}
+
+		for method, p := range patterns {
+			if e := urlPatternCache.Put(rail, method, p); e != nil {
+				return nil, fmt.Errorf("failed to store urlPatternCache, %w", e)
+			}
+		}
 		return nil, nil
 	})
 
 	return e
 }
 
-func toCachedUrlRes(epath ExtendedPathRes) CachedUrlRes {
-	cur := CachedUrlRes{
-		Id:      epath.Id,
-		Pgroup:  epath.Pgroup,
-		PathNo:  epath.PathNo,
-		ResCode: epath.ResCode,
-		Url:     epath.Url,
-		Method:  epath.Method,
-		Ptype:   epath.Ptype,
-	}
-	return cur
-}
-
-// preprocess url, the processed url will always starts with '/' and never ends with '/'
-func preprocessUrl(url string) string {
-	ru := []rune(strings.TrimSpace(url))
-	l := len(ru)
-	if l < 1 {
-		return "/"
-	}
-
-	j := strings.LastIndex(url, "?")
-	if j > -1 {
-		ru = ru[0:j]
-		l = len(ru)
-	}
+// Merge the joined rows of path and path_resource into CachedUrlRes, one for each path
+func toCachedUrlRes(paths []ExtendedPathRes) []CachedUrlRes {
+	idx := map[string]int{} // pathNo -> index in curs
+	curs := []CachedUrlRes{}
 
-	// never ends with '/'
-	if ru[l-1] == '/' && l > 1 {
-		lj := l - 1
-		for lj > 1 && ru[lj-1] == '/' {
-			lj -= 1
+	for _, ep := range paths {
+		i, ok := idx[ep.PathNo]
+		if !ok {
+			mode := ep.ResMode
+			if mode == "" {
+				mode = PrmAny
+			}
+			curs = append(curs, CachedUrlRes{
+				Id:       ep.Id,
+				Pgroup:   ep.Pgroup,
+				PathNo:   ep.PathNo,
+				ResCodes: []string{},
+				ResMode:  mode,
+				Url:      policy.PreprocessUrl(ep.Url),
+				Method:   ep.Method,
+				Ptype:    ep.Ptype,
+			})
+			i = len(curs) - 1
+			idx[ep.PathNo] = i
+		}
+		if ep.ResCode != "" {
+			curs[i].ResCodes = append(curs[i].ResCodes, ep.ResCode)
 		}
-
-		ru = ru[0:lj]
-	}
-
-	// always start with '/'
-	if ru[0] != '/' {
-		return "/" + string(ru)
 	}
-	return string(ru)
+	return curs
 }
 
-func findPathRes(pathNo string) (ExtendedPathRes, error) {
-	var ep ExtendedPathRes
+func findPathRes(pathNo string) (CachedUrlRes, error) {
+	var eps []ExtendedPathRes
 	tx := miso.GetMySQL().
-		Raw("select p.*, pr.res_code from path p left join path_resource pr on p.path_no = pr.path_no where p.path_no = ? limit 1", pathNo).
-		Scan(&ep)
+		Raw("select p.*, pr.res_code from path p left join path_resource pr on p.path_no = pr.path_no and pr.is_del = 0 where p.path_no = ? and p.is_del = 0", pathNo).
+		Scan(&eps)
 	if tx.Error != nil {
-		return ep, tx.Error
+		return CachedUrlRes{}, tx.Error
 	}
 
-	if tx.RowsAffected < 1 {
-		return ep, miso.NewErr("Path not found")
+	if len(eps) < 1 {
+		return CachedUrlRes{}, miso.NewErrCode(ErrCodePathNotFound, "Path not found")
 	}
 
-	return ep, nil
+	return toCachedUrlRes(eps)[0], nil
 }
 
 // global lock for resources
@@ -1079,6 +3062,11 @@ func lockPathExec(ec miso.Rail, pathNo string, runnable miso.Runnable) error {
 	return miso.RLockExec(ec, "goauth:path:"+pathNo, runnable)
 }
 
+// global lock for role inheritance
+func lockRoleParent(ec miso.Rail, runnable miso.LRunnable[any]) (any, error) {
+	return miso.RLockRun(ec, "goauth:role:parent", runnable)
+}
+
 // lock for role-resource cache
 func lockRoleResCache(ec miso.Rail, runnable miso.LRunnable[any]) (any, error) {
 	return miso.RLockRun(ec, "goauth:role:res:cache", runnable)
@@ -1086,7 +3074,7 @@ func lockRoleResCache(ec miso.Rail, runnable miso.LRunnable[any]) (any, error) {
 
 func listResCode(ec miso.Rail) ([]string, error) {
 	var codes []string
-	t := miso.GetMySQL().Raw("select code from resource").Scan(&codes)
+	t := miso.GetMySQL().Raw("select code from resource where is_del = 0").Scan(&codes)
 	if t.Error != nil {
 		return nil, t.Error
 	}
@@ -2,6 +2,7 @@ package goauth
 
 import (
 	"testing"
+	"time"
 
 	"github.com/curtisnewbie/gocommon/common"
 	"github.com/curtisnewbie/miso/miso"
@@ -26,10 +27,11 @@ func TestUpdatePath(t *testing.T) {
 		Type:   PtPublic,
 		Group:  "goauth",
 	}
-	e := UpdatePath(miso.EmptyRail(), req)
+	resp, e := UpdatePath(miso.EmptyRail(), req)
 	if e != nil {
 		t.Fatal(e)
 	}
+	t.Logf("%+v", resp)
 }
 
 func TestGetRoleInfo(t *testing.T) {
@@ -53,7 +55,7 @@ func TestCreatePathIfNotExist(t *testing.T) {
 		Url:   "/goauth/open/api/role/resource/add",
 		Group: "goauth",
 	}
-	e := CreatePathIfNotExist(miso.EmptyRail(), req, common.NilUser())
+	e := CreatePathIfNotExist(miso.EmptyRail(), req, "", common.NilUser())
 	if e != nil {
 		t.Fatal(e)
 	}
@@ -79,7 +81,7 @@ func TestCreateRes(t *testing.T) {
 		Name: "GoAuth Test  ",
 	}
 
-	e := CreateResourceIfNotExist(miso.EmptyRail(), req, common.NilUser())
+	e := CreateResourceIfNotExist(miso.EmptyRail(), req, "goauth", common.NilUser())
 	if e != nil {
 		t.Fatal(e)
 	}
@@ -99,36 +101,6 @@ func TestBindPathRes(t *testing.T) {
 	}
 }
 
-func TestPreprocessUrl(t *testing.T) {
-	if v := preprocessUrl(""); v != "/" {
-		t.Fatal(v)
-	}
-
-	if v := preprocessUrl("/"); v != "/" {
-		t.Fatal(v)
-	}
-
-	if v := preprocessUrl("///"); v != "/" {
-		t.Fatal(v)
-	}
-
-	if v := preprocessUrl("/goauth/test/path"); v != "/goauth/test/path" {
-		t.Fatal(v)
-	}
-
-	if v := preprocessUrl("/goauth/test/path//"); v != "/goauth/test/path" {
-		t.Fatal(v)
-	}
-
-	if v := preprocessUrl("goauth/test/path//"); v != "/goauth/test/path" {
-		t.Fatal(v)
-	}
-
-	if v := preprocessUrl("goauth/test/path?abc=123"); v != "/goauth/test/path" {
-		t.Fatal(v)
-	}
-}
-
 func TestUnbindPathRes(t *testing.T) {
 	before(t)
 
@@ -155,6 +127,53 @@ func TestAddRole(t *testing.T) {
 	}
 }
 
+func TestCloneRole(t *testing.T) {
+	before(t)
+	rail := miso.EmptyRail()
+
+	resCode := miso.GenIdP("test_res_")
+	if e := CreateResourceIfNotExist(rail, CreateResReq{Name: "Test Clone", Code: resCode}, "", common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+	defer DeleteResource(rail, DeleteResourceReq{ResCode: resCode})
+
+	srcName := miso.GenIdP("src_")
+	if e := AddRole(rail, AddRoleReq{Name: srcName}, common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+	var srcRoleNo string
+	if tx := miso.GetMySQL().Raw(`select role_no from role where name = ? and is_del = 0`, srcName).Scan(&srcRoleNo); tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	defer DeleteRole(rail, DeleteRoleReq{RoleNo: srcRoleNo})
+
+	if e := AddResToRoleIfNotExist(rail, AddRoleResReq{RoleNo: srcRoleNo, ResCode: resCode}, common.NilUser()); e != nil {
+		t.Fatal(e)
+	}
+
+	r, e := CloneRole(rail, CloneRoleReq{RoleNo: srcRoleNo, Name: "Clone " + srcName}, common.NilUser())
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer DeleteRole(rail, DeleteRoleReq{RoleNo: r.RoleNo})
+	t.Logf("%+v", r)
+
+	resp, e := ListRoleRes(rail, ListRoleResReq{RoleNo: r.RoleNo, Paging: miso.Paging{Limit: 10, Page: 1}})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(resp.Payload) != 1 || resp.Payload[0].ResCode != resCode {
+		t.Fatalf("cloned role should be granted %v, %+v", resCode, resp.Payload)
+	}
+}
+
+func TestDeleteDefaultAdminRole(t *testing.T) {
+	e := DeleteRole(miso.EmptyRail(), DeleteRoleReq{RoleNo: DefaultAdminRoleNo})
+	if e == nil {
+		t.Fatal("default administrator role should not be deleted")
+	}
+}
+
 func TestAddResToRole(t *testing.T) {
 	before(t)
 
@@ -269,3 +288,265 @@ func TestTestResourceAccess(t *testing.T) {
 		t.Fatal("should be valid")
 	}
 }
+
+func TestBatchTestResourceAccess(t *testing.T) {
+	before(t)
+
+	ec := miso.EmptyRail()
+	LoadPathResCache(ec)
+	LoadRoleResCache(ec)
+
+	roleNo := "role_555329954676736208429"
+	items := []ResAccessTestItem{
+		{Url: "/goauth/open/api/role/resource/add", Method: "POST"},
+		{Url: "/goauth/open/api/role/resource/add", Method: "POST"},
+		{Url: "/goauth/open/api/path/list", Method: "POST"},
+		{Url: "/goauth/not/exist", Method: "GET"},
+	}
+
+	resp, e := BatchTestResourceAccess(ec, BatchTestResAccessReq{RoleNo: roleNo, Items: items})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(resp.Items) != len(items) {
+		t.Fatalf("expected %d results, got %d", len(items), len(resp.Items))
+	}
+
+	// decisions made in batch are the same as the ones made one by one
+	for i, it := range items {
+		r, e := TestResourceAccess(ec, TestResAccessReq{RoleNo: roleNo, Url: it.Url, Method: it.Method})
+		if e != nil {
+			t.Fatal(e)
+		}
+		if resp.Items[i].Valid != r.Valid {
+			t.Fatalf("decision of %+v is %v in batch, but %v", it, resp.Items[i].Valid, r.Valid)
+		}
+	}
+}
+
+func TestBatchTestResourceAccessLimit(t *testing.T) {
+	items := make([]ResAccessTestItem, maxBatchAccessTestItems+1)
+	_, e := BatchTestResourceAccess(miso.EmptyRail(), BatchTestResAccessReq{RoleNo: "role_555329954676736208429", Items: items})
+	if e == nil {
+		t.Fatalf("more than %d items should be rejected", maxBatchAccessTestItems)
+	}
+}
+
+func TestAccessMemo(t *testing.T) {
+	ec := miso.EmptyRail()
+	memo := newAccessMemo()
+
+	// memoized lookups are shared without hitting the cache again
+	memo.urlRes["GET:/file/1"] = memoUrlRes{cur: CachedUrlRes{PathNo: "path_1", ResCodes: []string{"res_1"}}}
+	cur, e := memo.lookupUrlRes(ec, "/file/1", "GET", nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if cur.PathNo != "path_1" {
+		t.Fatalf("memoized path should be returned, %+v", cur)
+	}
+
+	memo.roleRes[roleResCacheKey("role_1", "res_1")] = true
+	ok, e := memo.checkRoleRes(ec, "role_1", "res_1")
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !ok {
+		t.Fatal("memoized grant should be returned")
+	}
+}
+
+func TestAccessMemoDeny(t *testing.T) {
+	before(t)
+	ec := miso.EmptyRail()
+	memo := newAccessMemo()
+
+	roleNo := miso.GenIdP("role_")
+	defer roleDenyCache.Del(ec, roleNo)
+	if e := roleDenyCache.Put(ec, roleNo, CachedRoleDeny{ResCodes: []string{}, PathNos: []string{"path_1"}}); e != nil {
+		t.Fatal(e)
+	}
+	d, e := memo.loadRolesDeny(ec, []string{roleNo}, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, ok := d.pathNos["path_1"]; !ok {
+		t.Fatalf("path_1 should be denied, %+v", d)
+	}
+
+	// the deny rules are changed, but the memoized ones are still returned without reloading
+	if e := roleDenyCache.Put(ec, roleNo, CachedRoleDeny{ResCodes: []string{}, PathNos: []string{"path_2"}}); e != nil {
+		t.Fatal(e)
+	}
+	d, e = memo.loadRolesDeny(ec, []string{roleNo}, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, ok := d.pathNos["path_1"]; !ok {
+		t.Fatalf("memoized deny rules should be returned, %+v", d)
+	}
+	if _, ok := d.pathNos["path_2"]; ok {
+		t.Fatalf("deny rules should not be reloaded, %+v", d)
+	}
+}
+
+func TestToCachedUrlRes(t *testing.T) {
+	paths := []ExtendedPathRes{
+		{PathNo: "path_1", Url: "/goauth/open/api/role/list/", Method: "POST", ResCode: "res_1", ResMode: PrmAll},
+		{PathNo: "path_1", Url: "/goauth/open/api/role/list/", Method: "POST", ResCode: "res_2", ResMode: PrmAll},
+		{PathNo: "path_2", Url: "/goauth/open/api/role/info", Method: "POST"},
+	}
+
+	curs := toCachedUrlRes(paths)
+	if len(curs) != 2 {
+		t.Fatalf("should have 2 paths, but got %v", len(curs))
+	}
+	if curs[0].Url != "/goauth/open/api/role/list" {
+		t.Fatalf("url is not preprocessed, %v", curs[0].Url)
+	}
+	if len(curs[0].ResCodes) != 2 || curs[0].ResMode != PrmAll {
+		t.Fatalf("path_1 should require all of 2 resources, %+v", curs[0])
+	}
+	if len(curs[1].ResCodes) != 0 || curs[1].ResMode != PrmAny {
+		t.Fatalf("path_2 should have no resource, %+v", curs[1])
+	}
+}
+
+func TestRolesDenyFilterRes(t *testing.T) {
+	d := rolesDeny{resCodes: map[string]struct{}{"res_1": {}}, pathNos: map[string]struct{}{"path_1": {}}}
+
+	if !d.deniesPath("path_1") || d.deniesPath("path_2") {
+		t.Fatal("only path_1 should be denied")
+	}
+
+	if res, ok := d.filterRes([]string{"res_1", "res_2"}, PrmAny); !ok || len(res) != 1 || res[0] != "res_2" {
+		t.Fatalf("res_2 should remain, %v, %v", res, ok)
+	}
+	if _, ok := d.filterRes([]string{"res_1"}, PrmAny); ok {
+		t.Fatal("res_1 is denied, nothing remains")
+	}
+	if _, ok := d.filterRes([]string{"res_1", "res_2"}, PrmAll); ok {
+		t.Fatal("res_1 is denied, all resources are required")
+	}
+	if res, ok := d.filterRes([]string{"res_2", "res_3"}, PrmAll); !ok || len(res) != 2 {
+		t.Fatalf("nothing should be denied, %v, %v", res, ok)
+	}
+}
+
+func TestDiffResBriefs(t *testing.T) {
+	a := []ResBrief{{Code: "c", Name: "C"}, {Code: "a", Name: "A"}, {Code: "b", Name: "B"}, {Code: "a", Name: "A"}}
+	b := []ResBrief{{Code: "b", Name: "B"}, {Code: "d", Name: "D"}}
+
+	onlyA, onlyB, both := diffResBriefs(a, b)
+	if len(onlyA) != 2 || onlyA[0].Code != "a" || onlyA[1].Code != "c" {
+		t.Fatalf("onlyA: %+v", onlyA)
+	}
+	if len(onlyB) != 1 || onlyB[0].Code != "d" {
+		t.Fatalf("onlyB: %+v", onlyB)
+	}
+	if len(both) != 1 || both[0].Code != "b" {
+		t.Fatalf("both: %+v", both)
+	}
+}
+
+func TestPlanRoleResChange(t *testing.T) {
+	bound := []string{"a", "b", "c"}
+	resCodes := []string{"b", "d", "d"}
+
+	toAdd, toRemove := planRoleResChange(bulkRoleResAdd, bound, resCodes)
+	if len(toAdd) != 1 || toAdd[0] != "d" || len(toRemove) != 0 {
+		t.Fatalf("add, toAdd: %v, toRemove: %v", toAdd, toRemove)
+	}
+
+	toAdd, toRemove = planRoleResChange(bulkRoleResRemove, bound, resCodes)
+	if len(toAdd) != 0 || len(toRemove) != 1 || toRemove[0] != "b" {
+		t.Fatalf("remove, toAdd: %v, toRemove: %v", toAdd, toRemove)
+	}
+
+	toAdd, toRemove = planRoleResChange(bulkRoleResReplace, bound, resCodes)
+	if len(toAdd) != 1 || toAdd[0] != "d" || len(toRemove) != 2 || toRemove[0] != "a" || toRemove[1] != "c" {
+		t.Fatalf("replace, toAdd: %v, toRemove: %v", toAdd, toRemove)
+	}
+
+	toAdd, toRemove = planRoleResChange(bulkRoleResReplace, bound, nil)
+	if len(toAdd) != 0 || len(toRemove) != 3 {
+		t.Fatalf("replace with nothing, toAdd: %v, toRemove: %v", toAdd, toRemove)
+	}
+}
+
+func TestRoleResExpired(t *testing.T) {
+	now := time.Now()
+	if roleResExpired(roleResCacheVal(nil), now) {
+		t.Fatal("grant without expiry should never expire")
+	}
+	future := now.Add(time.Minute)
+	if roleResExpired(roleResCacheVal(&future), now) {
+		t.Fatal("grant should not be expired yet")
+	}
+	past := now.Add(-time.Minute)
+	if !roleResExpired(roleResCacheVal(&past), now) {
+		t.Fatal("grant should be expired")
+	}
+}
+
+func TestIsAdminRole(t *testing.T) {
+	if !isAdminRole(DefaultAdminRoleNo) {
+		t.Fatal("default admin role should be administrator")
+	}
+	if isAdminRole("role_not_admin") {
+		t.Fatal("role should not be administrator")
+	}
+}
+
+func TestNormalizeRoleTags(t *testing.T) {
+	tags, e := normalizeRoleTags([]string{" ops ", "", "oncall", "ops"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(tags) != 2 || tags[0] != "ops" || tags[1] != "oncall" {
+		t.Fatalf("tags: %v", tags)
+	}
+
+	if _, e := normalizeRoleTags([]string{"a,b"}); e == nil {
+		t.Fatal("tag with ',' should be rejected")
+	}
+
+	if split := splitRoleTags("ops,oncall,"); len(split) != 2 {
+		t.Fatalf("split: %v", split)
+	}
+	if split := splitRoleTags(""); len(split) != 0 {
+		t.Fatalf("split: %v", split)
+	}
+}
+
+func TestOrderClause(t *testing.T) {
+	o, e := orderClause("", "", roleSortColumns)
+	if e != nil || o != "id DESC" {
+		t.Fatalf("%v, %v", o, e)
+	}
+	o, e = orderClause("createTime", "asc", roleSortColumns)
+	if e != nil || o != "create_time ASC, id ASC" {
+		t.Fatalf("%v, %v", o, e)
+	}
+	if _, e = orderClause("name; drop table role", "", roleSortColumns); e == nil {
+		t.Fatal("unknown column should be rejected")
+	}
+	if _, e = orderClause("code", "sideways", resSortColumns); e == nil {
+		t.Fatal("invalid order should be rejected")
+	}
+}
+
+func TestGetUrlPatternTrie(t *testing.T) {
+	patterns := []string{"/file/{fileId}", "/file/*"}
+	t1 := getUrlPatternTrie("GET", patterns)
+	if t2 := getUrlPatternTrie("GET", []string{"/file/{fileId}", "/file/*"}); t2 != t1 {
+		t.Fatal("trie should be reused when patterns are unchanged")
+	}
+	t3 := getUrlPatternTrie("GET", []string{"/file/{fileId}"})
+	if t3 == t1 {
+		t.Fatal("trie should be rebuilt when patterns are changed")
+	}
+	if p, ok := t3.Match("/file/123"); !ok || p != "/file/{fileId}" {
+		t.Fatalf("unexpected match, %v, %v", p, ok)
+	}
+}
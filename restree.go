@@ -0,0 +1,74 @@
+package goauth
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/curtisnewbie/goauth/policy"
+	"github.com/curtisnewbie/miso/miso"
+)
+
+// Node of resources grouped by the dotted hierarchy of the codes, e.g., 'vfm.file.read' is under node 'vfm.file'
+type ResNode struct {
+	Prefix    string     `json:"prefix"`    // prefix of the codes, e.g., 'vfm.file', empty for the root
+	Grant     string     `json:"grant"`     // code that grants the whole subtree, e.g., 'vfm.file.*', empty for the root
+	Resources []ResBrief `json:"resources"` // resources directly under the prefix
+	Children  []ResNode  `json:"children"`
+}
+
+// List resources as a tree, only the candidates for the role are included if roleNo is present
+func ListResourceTree(ec miso.Rail, roleNo string) (ResNode, error) {
+	var res []ResBrief
+	var e error
+	if roleNo != "" {
+		res, e = ListResourceCandidatesForRole(ec, roleNo)
+	} else {
+		res, e = ListAllResBriefs(ec)
+	}
+	if e != nil {
+		return ResNode{}, e
+	}
+	return buildResTree(res), nil
+}
+
+type resTreeNode struct {
+	prefix    string
+	resources []ResBrief
+	children  map[string]*resTreeNode
+}
+
+func buildResTree(res []ResBrief) ResNode {
+	root := &resTreeNode{children: map[string]*resTreeNode{}}
+	for _, r := range res {
+		n := root
+		segs := strings.Split(r.Code, policy.ResCodeSeparator)
+		for i := 0; i < len(segs)-1; i++ {
+			prefix := strings.Join(segs[:i+1], policy.ResCodeSeparator)
+			c, ok := n.children[prefix]
+			if !ok {
+				c = &resTreeNode{prefix: prefix, children: map[string]*resTreeNode{}}
+				n.children[prefix] = c
+			}
+			n = c
+		}
+		n.resources = append(n.resources, r)
+	}
+	return root.toResNode()
+}
+
+func (n *resTreeNode) toResNode() ResNode {
+	rn := ResNode{Prefix: n.prefix, Resources: n.resources, Children: make([]ResNode, 0, len(n.children))}
+	if n.prefix != "" {
+		rn.Grant = n.prefix + policy.ResCodeWildcardSuffix
+	}
+	if rn.Resources == nil {
+		rn.Resources = []ResBrief{}
+	}
+	sort.Slice(rn.Resources, func(i, j int) bool { return rn.Resources[i].Code < rn.Resources[j].Code })
+
+	for _, c := range n.children {
+		rn.Children = append(rn.Children, c.toResNode())
+	}
+	sort.Slice(rn.Children, func(i, j int) bool { return rn.Children[i].Prefix < rn.Children[j].Prefix })
+	return rn
+}
@@ -0,0 +1,35 @@
+package goauth
+
+import "testing"
+
+func TestBuildResTree(t *testing.T) {
+	root := buildResTree([]ResBrief{
+		{Code: "vfm.file.write"},
+		{Code: "manage-resources"},
+		{Code: "vfm.file.read"},
+		{Code: "vfm.dir"},
+	})
+
+	if root.Prefix != "" || root.Grant != "" {
+		t.Fatalf("root: %+v", root)
+	}
+	if len(root.Resources) != 1 || root.Resources[0].Code != "manage-resources" {
+		t.Fatalf("root resources: %+v", root.Resources)
+	}
+	if len(root.Children) != 1 || root.Children[0].Prefix != "vfm" || root.Children[0].Grant != "vfm.*" {
+		t.Fatalf("root children: %+v", root.Children)
+	}
+
+	vfm := root.Children[0]
+	if len(vfm.Resources) != 1 || vfm.Resources[0].Code != "vfm.dir" {
+		t.Fatalf("vfm resources: %+v", vfm.Resources)
+	}
+	if len(vfm.Children) != 1 || vfm.Children[0].Grant != "vfm.file.*" {
+		t.Fatalf("vfm children: %+v", vfm.Children)
+	}
+
+	file := vfm.Children[0]
+	if len(file.Resources) != 2 || file.Resources[0].Code != "vfm.file.read" || file.Resources[1].Code != "vfm.file.write" {
+		t.Fatalf("file resources: %+v", file.Resources)
+	}
+}
@@ -0,0 +1,85 @@
+-- migration scripts for existing databases, execute the statements introduced after the version in use
+
+-- multiple resources per path
+ALTER TABLE goauth.path ADD COLUMN `res_mode` varchar(10) NOT NULL DEFAULT 'ANY' COMMENT 'resource mode: ANY (any of the resources is required), ALL (all of the resources are required)' AFTER `ptype`;
+
+-- role inheritance
+CREATE TABLE IF NOT EXISTS goauth.role_parent (
+  `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'role no',
+  `parent_role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'parent role no',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
+  `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
+  `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
+  `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  PRIMARY KEY (`id`),
+  KEY `role_no` (`role_no`),
+  KEY `parent_role_no` (`parent_role_no`)
+) ENGINE=InnoDB COMMENT='Role parents, a role inherits resources of its parents';
+
+-- deny rules
+CREATE TABLE IF NOT EXISTS goauth.role_deny (
+  `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'role no',
+  `res_code` varchar(32) NOT NULL DEFAULT '' COMMENT 'resource code denied, either res_code or path_no is present',
+  `path_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'path no denied, either res_code or path_no is present',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
+  `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
+  `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
+  `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  PRIMARY KEY (`id`),
+  KEY `role_no` (`role_no`)
+) ENGINE=InnoDB COMMENT='Role deny rules, deny rules override the resources granted';
+
+-- access decision audit log
+CREATE TABLE IF NOT EXISTS goauth.access_decision (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `ctime` timestamp(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT 'when the decision is made',
+  `role_nos` varchar(255) NOT NULL DEFAULT '' COMMENT 'role nos, joined with comma',
+  `method` varchar(10) NOT NULL DEFAULT '' COMMENT 'http method',
+  `url` varchar(128) NOT NULL DEFAULT '' COMMENT 'url',
+  `path_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'path matched',
+  `res_codes` varchar(255) NOT NULL DEFAULT '' COMMENT 'resources required by the path, joined with comma',
+  `valid` tinyint NOT NULL DEFAULT '0' COMMENT '0-forbidden, 1-permitted',
+  `reason` varchar(255) NOT NULL DEFAULT '' COMMENT 'why the access is permitted or forbidden',
+  PRIMARY KEY (`id`),
+  KEY `ctime` (`ctime`)
+) ENGINE=InnoDB COMMENT='Access decisions audited';
+
+-- expiring role resources
+ALTER TABLE goauth.role_resource ADD COLUMN `expires_at` timestamp NULL DEFAULT NULL COMMENT 'when the grant expires, null if it never expires' AFTER `res_code`;
+ALTER TABLE goauth.role_resource ADD KEY `expires_at` (`expires_at`);
+
+-- role metadata
+ALTER TABLE goauth.role ADD COLUMN `desc` varchar(255) NOT NULL DEFAULT '' COMMENT 'description' AFTER `name`;
+ALTER TABLE goauth.role ADD COLUMN `owner` varchar(64) NOT NULL DEFAULT '' COMMENT 'owning team' AFTER `desc`;
+ALTER TABLE goauth.role ADD COLUMN `contact` varchar(128) NOT NULL DEFAULT '' COMMENT 'contact of the owner' AFTER `owner`;
+ALTER TABLE goauth.role ADD COLUMN `tags` varchar(255) NOT NULL DEFAULT '' COMMENT 'tags joined with comma' AFTER `contact`;
+
+-- resource description and category
+ALTER TABLE goauth.resource ADD COLUMN `desc` varchar(255) NOT NULL DEFAULT '' COMMENT 'description' AFTER `name`;
+ALTER TABLE goauth.resource ADD COLUMN `category` varchar(32) NOT NULL DEFAULT '' COMMENT 'category' AFTER `desc`;
+
+-- service that reported the resources and paths
+ALTER TABLE goauth.resource ADD COLUMN `service` varchar(64) NOT NULL DEFAULT '' COMMENT 'service that reported the resource' AFTER `category`;
+ALTER TABLE goauth.resource ADD KEY `service` (`service`);
+ALTER TABLE goauth.path ADD COLUMN `service` varchar(64) NOT NULL DEFAULT '' COMMENT 'service that reported the path' AFTER `res_mode`;
+ALTER TABLE goauth.path ADD KEY `service` (`service`);
+UPDATE goauth.path SET `service` = `pgroup` WHERE `service` = '';
+
+-- stale paths
+ALTER TABLE goauth.path ADD COLUMN `stale_since` timestamp NULL DEFAULT NULL COMMENT 'when the path is no longer reported by the service, null if it is not stale' AFTER `service`;
+
+-- paths edited manually
+ALTER TABLE goauth.path ADD COLUMN `manual` tinyint NOT NULL DEFAULT '0' COMMENT 'whether the url or method is edited manually, such paths are never marked stale' AFTER `stale_since`;
+
+-- deletion batch
+ALTER TABLE goauth.path ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
+ALTER TABLE goauth.path_resource ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
+ALTER TABLE goauth.resource ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
+ALTER TABLE goauth.role_resource ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
+ALTER TABLE goauth.role ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
+ALTER TABLE goauth.role_parent ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
+ALTER TABLE goauth.role_deny ADD COLUMN `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no' AFTER `is_del`;
@@ -8,13 +8,19 @@ CREATE TABLE IF NOT EXISTS goauth.path (
   `method` varchar(10) NOT NULL DEFAULT ''  COMMENT 'http method',
   `url` varchar(128) NOT NULL DEFAULT '' COMMENT 'path url',
   `ptype` varchar(10) NOT NULL DEFAULT '' COMMENT 'path type: PROTECTED, PUBLIC',
+  `res_mode` varchar(10) NOT NULL DEFAULT 'ANY' COMMENT 'resource mode: ANY (any of the resources is required), ALL (all of the resources are required)',
+  `service` varchar(64) NOT NULL DEFAULT '' COMMENT 'service that reported the path',
+  `stale_since` timestamp NULL DEFAULT NULL COMMENT 'when the path is no longer reported by the service, null if it is not stale',
+  `manual` tinyint NOT NULL DEFAULT '0' COMMENT 'whether the url or method is edited manually, such paths are never marked stale',
   `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
   `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
   `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
   `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
   PRIMARY KEY (`id`),
-  KEY `path_no` (`path_no`)
+  KEY `path_no` (`path_no`),
+  KEY `service` (`service`)
 ) ENGINE=InnoDB COMMENT='Paths';
 
 CREATE TABLE IF NOT EXISTS goauth.path_resource (
@@ -26,6 +32,7 @@ CREATE TABLE IF NOT EXISTS goauth.path_resource (
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
   `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
   `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
   PRIMARY KEY (`id`),
   KEY (`path_no`, `res_code`)
 ) ENGINE=InnoDB COMMENT='Path Resource';
@@ -34,40 +41,97 @@ CREATE TABLE IF NOT EXISTS goauth.resource (
   `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
   `code` varchar(32) NOT NULL DEFAULT '' COMMENT 'resource code',
   `name` varchar(32) NOT NULL DEFAULT '' COMMENT 'resource name',
+  `desc` varchar(255) NOT NULL DEFAULT '' COMMENT 'description',
+  `category` varchar(32) NOT NULL DEFAULT '' COMMENT 'category',
+  `service` varchar(64) NOT NULL DEFAULT '' COMMENT 'service that reported the resource',
   `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
   `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
   `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
   `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
   PRIMARY KEY (`id`),
-  KEY `code` (`code`)
+  KEY `code` (`code`),
+  KEY `service` (`service`)
 ) ENGINE=InnoDB COMMENT='Resources';
 
 CREATE TABLE IF NOT EXISTS goauth.role_resource (
   `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
   `role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'role no',
   `res_code` varchar(32) NOT NULL DEFAULT '' COMMENT 'resource code',
+  `expires_at` timestamp NULL DEFAULT NULL COMMENT 'when the grant expires, null if it never expires',
   `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
   `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
   `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
   `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
   PRIMARY KEY (`id`),
-  KEY `role_no` (`role_no`)
+  KEY `role_no` (`role_no`),
+  KEY `expires_at` (`expires_at`)
 ) ENGINE=InnoDB COMMENT='Role resources';
 
 CREATE TABLE IF NOT EXISTS goauth.role (
   `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
   `role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'role no',
   `name` varchar(32) NOT NULL DEFAULT '' COMMENT 'name of role',
+  `desc` varchar(255) NOT NULL DEFAULT '' COMMENT 'description',
+  `owner` varchar(64) NOT NULL DEFAULT '' COMMENT 'owning team',
+  `contact` varchar(128) NOT NULL DEFAULT '' COMMENT 'contact of the owner',
+  `tags` varchar(255) NOT NULL DEFAULT '' COMMENT 'tags joined with comma',
   `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
   `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
   `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
   `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
   `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
   PRIMARY KEY (`id`),
   KEY `role_no` (`role_no`)
 ) ENGINE=InnoDB COMMENT='Roles';
 
+CREATE TABLE IF NOT EXISTS goauth.role_parent (
+  `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'role no',
+  `parent_role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'parent role no',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
+  `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
+  `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
+  `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
+  PRIMARY KEY (`id`),
+  KEY `role_no` (`role_no`),
+  KEY `parent_role_no` (`parent_role_no`)
+) ENGINE=InnoDB COMMENT='Role parents, a role inherits resources of its parents';
+
+CREATE TABLE IF NOT EXISTS goauth.role_deny (
+  `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `role_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'role no',
+  `res_code` varchar(32) NOT NULL DEFAULT '' COMMENT 'resource code denied, either res_code or path_no is present',
+  `path_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'path no denied, either res_code or path_no is present',
+  `create_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'when the record is created',
+  `create_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who created this record',
+  `update_time` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'when the record is updated',
+  `update_by` varchar(255) NOT NULL DEFAULT '' COMMENT 'who updated this record',
+  `is_del` tinyint NOT NULL DEFAULT '0' COMMENT '0-normal, 1-deleted',
+  `del_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'deletion batch no, rows deleted together share the same no',
+  PRIMARY KEY (`id`),
+  KEY `role_no` (`role_no`)
+) ENGINE=InnoDB COMMENT='Role deny rules, deny rules override the resources granted';
+
 -- default one for administrator, with this role, all paths can be accessed
 INSERT INTO goauth.role(role_no, name) VALUES ('role_554107924873216177918', 'Super Administrator');
+
+CREATE TABLE IF NOT EXISTS goauth.access_decision (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'primary key',
+  `ctime` timestamp(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) COMMENT 'when the decision is made',
+  `role_nos` varchar(255) NOT NULL DEFAULT '' COMMENT 'role nos, joined with comma',
+  `method` varchar(10) NOT NULL DEFAULT '' COMMENT 'http method',
+  `url` varchar(128) NOT NULL DEFAULT '' COMMENT 'url',
+  `path_no` varchar(32) NOT NULL DEFAULT '' COMMENT 'path matched',
+  `res_codes` varchar(255) NOT NULL DEFAULT '' COMMENT 'resources required by the path, joined with comma',
+  `valid` tinyint NOT NULL DEFAULT '0' COMMENT '0-forbidden, 1-permitted',
+  `reason` varchar(255) NOT NULL DEFAULT '' COMMENT 'why the access is permitted or forbidden',
+  PRIMARY KEY (`id`),
+  KEY `ctime` (`ctime`)
+) ENGINE=InnoDB COMMENT='Access decisions audited';
@@ -9,7 +9,9 @@ func BootstrapServer(args []string) {
 	common.LoadBuiltinPropagationKeys()
 	miso.PreServerBootstrap(ScheduleTasks)
 	miso.PreServerBootstrap(SubEventBus)
+	miso.PreServerBootstrap(BootstrapAuditor)
 	miso.PreServerBootstrap(RegisterWebEndpoints)
 	miso.PostServerBootstrapped(CreateMonitoredServiceWatches)
+	miso.PostServerBootstrapped(BootstrapGrpcServer)
 	miso.BootstrapServer(args)
 }
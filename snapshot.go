@@ -0,0 +1,134 @@
+package goauth
+
+import (
+	"time"
+
+	"github.com/curtisnewbie/goauth/policy"
+	"github.com/curtisnewbie/miso/miso"
+)
+
+// Build snapshot of all the paths and roles for in-process evaluators, see policy.Evaluator
+func PolicySnapshot(rail miso.Rail) (policy.Snapshot, error) {
+	var eps []ExtendedPathRes
+	tx := miso.GetMySQL().
+		Raw("select p.*, pr.res_code from path p left join path_resource pr on p.path_no = pr.path_no and pr.is_del = 0 where p.is_del = 0").
+		Scan(&eps)
+	if tx.Error != nil {
+		return policy.Snapshot{}, tx.Error
+	}
+
+	paths := []policy.Path{}
+	for _, cur := range toCachedUrlRes(eps) {
+		paths = append(paths, toPolicyPath(cur))
+	}
+
+	roleNos, e := listRoleNos(rail)
+	if e != nil {
+		return policy.Snapshot{}, e
+	}
+
+	roles := make([]policy.Role, 0, len(roleNos))
+	for _, roleNo := range roleNos {
+		r, e := loadPolicyRole(roleNo)
+		if e != nil {
+			return policy.Snapshot{}, e
+		}
+		roles = append(roles, r)
+	}
+
+	return policy.Snapshot{Paths: paths, Roles: roles, AdminRoleNos: adminRoleNos()}, nil
+}
+
+func LoadPolicyPath(rail miso.Rail, pathNo string) (policy.PathResp, error) {
+	var id int
+	tx := miso.GetMySQL().Raw(`select id from path where path_no = ? and is_del = 0 limit 1`, pathNo).Scan(&id)
+	if tx.Error != nil {
+		return policy.PathResp{}, tx.Error
+	}
+	if id < 1 {
+		return policy.PathResp{Found: false}, nil
+	}
+
+	cur, e := findPathRes(pathNo)
+	if e != nil {
+		return policy.PathResp{}, e
+	}
+	return policy.PathResp{Found: true, Path: toPolicyPath(cur)}, nil
+}
+
+func LoadPolicyRole(rail miso.Rail, roleNo string) (policy.RoleResp, error) {
+	var id int
+	tx := miso.GetMySQL().Raw(`select id from role where role_no = ? and is_del = 0 limit 1`, roleNo).Scan(&id)
+	if tx.Error != nil {
+		return policy.RoleResp{}, tx.Error
+	}
+	if id < 1 {
+		return policy.RoleResp{Found: false}, nil
+	}
+
+	r, e := loadPolicyRole(roleNo)
+	if e != nil {
+		return policy.RoleResp{}, e
+	}
+	return policy.RoleResp{Found: true, Role: r}, nil
+}
+
+func loadPolicyRole(roleNo string) (policy.Role, error) {
+	grants, e := listEffectiveResGrants(roleNo)
+	if e != nil {
+		return policy.Role{}, e
+	}
+	resCodes := make([]string, 0, len(grants))
+	resExpiresAt := map[string]time.Time{}
+	for _, g := range grants {
+		resCodes = append(resCodes, g.ResCode)
+		if g.ExpiresAt != nil {
+			resExpiresAt[g.ResCode] = *g.ExpiresAt
+		}
+	}
+	deny, e := listEffectiveDeny(roleNo)
+	if e != nil {
+		return policy.Role{}, e
+	}
+	return policy.Role{
+		RoleNo:       roleNo,
+		ResCodes:     resCodes,
+		ResExpiresAt: resExpiresAt,
+		DenyResCodes: deny.ResCodes,
+		DenyPathNos:  deny.PathNos,
+	}, nil
+}
+
+func toPolicyPath(cur CachedUrlRes) policy.Path {
+	return policy.Path{
+		PathNo:   cur.PathNo,
+		Url:      cur.Url,
+		Method:   cur.Method,
+		Ptype:    cur.Ptype,
+		ResCodes: cur.ResCodes,
+		ResMode:  cur.ResMode,
+	}
+}
+
+// Notify in-process evaluators that the path is changed
+func publishPathChange(rail miso.Rail, pathNo string) {
+	publishPolicyChange(rail, policy.ChangeEvent{Type: policy.CtPath, PathNo: pathNo})
+}
+
+// Notify in-process evaluators that the roles are changed
+func publishRoleChange(rail miso.Rail, roleNos ...string) {
+	for _, roleNo := range roleNos {
+		publishPolicyChange(rail, policy.ChangeEvent{Type: policy.CtRole, RoleNo: roleNo})
+	}
+}
+
+// Notify in-process evaluators that everything may be changed
+func publishAllChange(rail miso.Rail) {
+	publishPolicyChange(rail, policy.ChangeEvent{Type: policy.CtAll})
+}
+
+func publishPolicyChange(rail miso.Rail, ev policy.ChangeEvent) {
+	if e := miso.PublishJson(rail, ev, policy.ChangeExchange, ""); e != nil {
+		rail.Errorf("Failed to publish policy change, %+v, %v", ev, e)
+	}
+}
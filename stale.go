@@ -0,0 +1,83 @@
+package goauth
+
+import (
+	"strings"
+	"time"
+
+	"github.com/curtisnewbie/goauth/policy"
+	"github.com/curtisnewbie/miso/miso"
+)
+
+const (
+	// whether stale paths are deleted once the grace period is elapsed
+	PropStalePathPruneEnabled = "goauth.path.stale.prune-enabled"
+
+	// grace period (in minutes) before stale paths are deleted
+	PropStalePathGracePeriod = "goauth.path.stale.grace-period-minutes"
+)
+
+func init() {
+	miso.SetDefProp(PropStalePathPruneEnabled, true)
+	miso.SetDefProp(PropStalePathGracePeriod, 1440)
+}
+
+// Mark paths of the service that are no longer reported as stale, the stale paths that are reported again are restored.
+// Paths with url or method edited manually are never marked stale.
+//
+// Nothing is marked if the service reports no path at all, it's more likely a misconfiguration.
+func MarkStalePaths(rail miso.Rail, service string, reported []CreatePathReq) error {
+	if service == "" {
+		return nil
+	}
+	if len(reported) < 1 {
+		rail.Warnf("Service '%s' reported no path, skipped marking stale paths", service)
+		return nil
+	}
+
+	pathNos := make([]string, 0, len(reported))
+	for _, r := range reported {
+		pathNos = append(pathNos, genPathNo(strings.TrimSpace(r.Group), policy.PreprocessUrl(r.Url), strings.ToUpper(strings.TrimSpace(r.Method))))
+	}
+
+	db := miso.GetMySQL()
+	tx := db.Exec(`update path set stale_since = ? where service = ? and is_del = 0 and manual = 0 and stale_since is null and path_no not in ?`,
+		time.Now(), service, pathNos)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		rail.Infof("Marked %d paths of service '%s' as stale", tx.RowsAffected, service)
+	}
+
+	tx = db.Exec(`update path set stale_since = null where service = ? and is_del = 0 and stale_since is not null and path_no in ?`,
+		service, pathNos)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected > 0 {
+		rail.Infof("Restored %d stale paths of service '%s'", tx.RowsAffected, service)
+	}
+	return nil
+}
+
+// Delete paths that have been stale for longer than 'goauth.path.stale.grace-period-minutes'
+func PruneStalePaths(rail miso.Rail) error {
+	if !miso.GetPropBool(PropStalePathPruneEnabled) {
+		return nil
+	}
+
+	before := time.Now().Add(-time.Duration(miso.GetPropInt(PropStalePathGracePeriod)) * time.Minute)
+	var pathNos []string
+	tx := miso.GetMySQL().Raw(`select path_no from path where is_del = 0 and manual = 0 and stale_since is not null and stale_since < ?`, before).Scan(&pathNos)
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	for _, pathNo := range pathNos {
+		if e := DeletePath(rail, DeletePathReq{PathNo: pathNo}); e != nil {
+			return e
+		}
+		rail.Infof("Pruned stale path '%s'", pathNo)
+	}
+	return nil
+}
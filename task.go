@@ -16,6 +16,16 @@ func ScheduleTasks(rail miso.Rail) error {
 	if err != nil {
 		return err
 	}
+	err = miso.ScheduleDistributedTask(miso.Job{
+		Cron:                   "* * * * *",
+		CronWithSeconds:        false,
+		Name:                   "PurgeExpiredRoleResTask",
+		TriggeredOnBoostrapped: true,
+		Run:                    PurgeExpiredRoleRes,
+	})
+	if err != nil {
+		return err
+	}
 	err = miso.ScheduleDistributedTask(miso.Job{
 		Cron:                   "*/15 * * * *",
 		CronWithSeconds:        false,
@@ -36,5 +46,25 @@ func ScheduleTasks(rail miso.Rail) error {
 	if err != nil {
 		return err
 	}
+	err = miso.ScheduleDistributedTask(miso.Job{
+		Cron:                   "*/10 * * * *",
+		CronWithSeconds:        false,
+		Name:                   "PruneStalePathTask",
+		TriggeredOnBoostrapped: false,
+		Run:                    PruneStalePaths,
+	})
+	if err != nil {
+		return err
+	}
+	err = miso.ScheduleDistributedTask(miso.Job{
+		Cron:                   "0 * * * *",
+		CronWithSeconds:        false,
+		Name:                   "PurgeAccessDecisionTask",
+		TriggeredOnBoostrapped: false,
+		Run:                    PurgeAccessDecisions,
+	})
+	if err != nil {
+		return err
+	}
 	return nil
 }
@@ -0,0 +1,432 @@
+package goauth
+
+import (
+	"github.com/curtisnewbie/goauth/policy"
+	"github.com/curtisnewbie/miso/miso"
+	"gorm.io/gorm"
+)
+
+const (
+	TrashTypePath     = "PATH"
+	TrashTypeResource = "RESOURCE"
+	TrashTypeRole     = "ROLE"
+)
+
+type ListTrashReq struct {
+	Type   string      `json:"type" validation:"notEmpty"` // PATH, RESOURCE or ROLE
+	Name   string      `json:"name"`                       // optional, fuzzy match on url of path, name of resource or role
+	Paging miso.Paging `json:"pagingVo"`
+}
+
+type TrashItem struct {
+	Id         int        `json:"id"`
+	No         string     `json:"no"`         // path no, resource code or role no
+	Name       string     `json:"name"`       // method and url of path, name of resource or role
+	DeleteTime miso.ETime `json:"deleteTime"` // when it's deleted
+}
+
+type ListTrashResp struct {
+	Paging  miso.Paging `json:"pagingVo"`
+	Payload []TrashItem `json:"payload"`
+}
+
+type RestoreTrashReq struct {
+	Type string `json:"type" validation:"notEmpty"` // PATH, RESOURCE or ROLE
+	Id   int    `json:"id"`                         // id of the item in trash
+}
+
+// Soft-deleted binding, i.e., role_resource, path_resource, role_deny or role_parent
+type trashBinding struct {
+	Id           int
+	RoleNo       string
+	ParentRoleNo string
+	ResCode      string
+	PathNo       string
+}
+
+// Soft-deleted entity, i.e., path, resource or role
+type trashEntity struct {
+	Id     int
+	No     string
+	Method string
+	Url    string
+	DelNo  string
+}
+
+// Generate deletion batch no, the entity and its bindings deleted together share the same del_no, so that they can be
+// restored together. Bindings removed one by one are not given a del_no, they are never restored.
+func genDelNo() string {
+	return miso.GenIdP("del_")
+}
+
+// List paths, resources or roles deleted
+func ListTrash(ec miso.Rail, req ListTrashReq) (ListTrashResp, error) {
+	var table, sel, nameCol string
+	switch req.Type {
+	case TrashTypePath:
+		table, sel, nameCol = "path", "id, path_no 'no', concat(method, ' ', url) 'name', update_time 'delete_time'", "url"
+	case TrashTypeResource:
+		table, sel, nameCol = "resource", "id, code 'no', name, update_time 'delete_time'", "name"
+	case TrashTypeRole:
+		table, sel, nameCol = "role", "id, role_no 'no', name, update_time 'delete_time'", "name"
+	default:
+		return ListTrashResp{}, miso.NewErrCode(ErrCodeIllegalArgument, "Invalid trash type")
+	}
+
+	applyCond := func(t *gorm.DB) *gorm.DB {
+		t = t.Where("is_del = 1 and del_no != ''")
+		if req.Name != "" {
+			t = t.Where(nameCol+" LIKE ?", "%"+req.Name+"%")
+		}
+		return t
+	}
+
+	var items []TrashItem
+	tx := miso.GetMySQL().
+		Table(table).
+		Select(sel).
+		Order("update_time DESC, id DESC")
+
+	tx = applyCond(tx).
+		Offset(req.Paging.GetOffset()).
+		Limit(req.Paging.GetLimit()).
+		Scan(&items)
+	if tx.Error != nil {
+		return ListTrashResp{}, tx.Error
+	}
+	if items == nil {
+		items = []TrashItem{}
+	}
+
+	var count int
+	tx = miso.GetMySQL().
+		Table(table).
+		Select("COUNT(*)")
+
+	tx = applyCond(tx).
+		Scan(&count)
+	if tx.Error != nil {
+		return ListTrashResp{}, tx.Error
+	}
+
+	return ListTrashResp{Paging: miso.RespPage(req.Paging, count), Payload: items}, nil
+}
+
+// Restore path, resource or role deleted, the bindings deleted together are restored as well unless they are no longer valid
+func RestoreTrash(ec miso.Rail, req RestoreTrashReq) error {
+	switch req.Type {
+	case TrashTypePath:
+		return restorePath(ec, req.Id)
+	case TrashTypeResource:
+		return restoreResource(ec, req.Id)
+	case TrashTypeRole:
+		return restoreRole(ec, req.Id)
+	}
+	return miso.NewErrCode(ErrCodeIllegalArgument, "Invalid trash type")
+}
+
+func findTrashEntity(query string, id int) (trashEntity, error) {
+	var ent trashEntity
+	tx := miso.GetMySQL().Raw(query, id).Scan(&ent)
+	if tx.Error != nil {
+		return ent, tx.Error
+	}
+	if ent.Id < 1 {
+		return ent, miso.NewErrCode(ErrCodeTrashItemNotFound, "Item not found in trash")
+	}
+	return ent, nil
+}
+
+func findTrashBindings(tx *gorm.DB, query string, args ...any) ([]trashBinding, error) {
+	var bindings []trashBinding
+	if t := tx.Raw(query, args...).Scan(&bindings); t.Error != nil {
+		return nil, t.Error
+	}
+	return bindings, nil
+}
+
+func restoreBindings(tx *gorm.DB, table string, bindings []trashBinding) error {
+	if len(bindings) < 1 {
+		return nil
+	}
+	ids := make([]int, 0, len(bindings))
+	for _, b := range bindings {
+		ids = append(ids, b.Id)
+	}
+	return tx.Exec(`update `+table+` set is_del = 0, del_no = '' where id in ?`, ids).Error
+}
+
+// Restore the soft-deleted entity. If the entity has been created again (e.g., re-reported by the monitored service),
+// the deleted row is only taken out of the trash, and the bindings are attached to the live one.
+func restoreTrashEntity(tx *gorm.DB, table string, id int, liveId int) error {
+	if liveId > 0 {
+		return tx.Exec(`update `+table+` set del_no = '' where id = ?`, id).Error
+	}
+	return tx.Exec(`update `+table+` set is_del = 0, del_no = '' where id = ?`, id).Error
+}
+
+func restorePath(ec miso.Rail, id int) error {
+	ent, e := findTrashEntity(`select id, path_no 'no', method, url, del_no from path where id = ? and is_del = 1 and del_no != ''`, id)
+	if e != nil {
+		return e
+	}
+
+	var denies []trashBinding
+	_, e = lockPath(ec, ent.No, func() (any, error) {
+		var liveId int
+		if t := miso.GetMySQL().Raw(`select id from path where path_no = ? and is_del = 0 limit 1`, ent.No).Scan(&liveId); t.Error != nil {
+			return nil, t.Error
+		}
+
+		return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+			if e := restoreTrashEntity(tx, "path", ent.Id, liveId); e != nil {
+				return e
+			}
+
+			pathRes, e := findTrashBindings(tx, `select pr.id, pr.path_no, pr.res_code from path_resource pr
+				where pr.path_no = ? and pr.is_del = 1 and pr.del_no = ?
+				and exists (select * from resource r where r.code = pr.res_code and r.is_del = 0)
+				and not exists (select * from path_resource x where x.path_no = pr.path_no and x.res_code = pr.res_code and x.is_del = 0)`,
+				ent.No, ent.DelNo)
+			if e != nil {
+				return e
+			}
+			if e := restoreBindings(tx, "path_resource", pathRes); e != nil {
+				return e
+			}
+
+			denies, e = findTrashBindings(tx, `select rd.id, rd.role_no, rd.res_code, rd.path_no from role_deny rd
+				where rd.path_no = ? and rd.is_del = 1 and rd.del_no = ?
+				and exists (select * from role ro where ro.role_no = rd.role_no and ro.is_del = 0)
+				and not exists (select * from role_deny x where x.role_no = rd.role_no and x.res_code = rd.res_code and x.path_no = rd.path_no and x.is_del = 0)`,
+				ent.No, ent.DelNo)
+			if e != nil {
+				return e
+			}
+			return restoreBindings(tx, "role_deny", denies)
+		})
+	})
+	if e != nil {
+		return e
+	}
+	ec.Infof("Restored path %s '%s %s'", ent.No, ent.Method, ent.Url)
+
+	if err := pathNoCache.Put(ec, ent.No, "1"); err != nil {
+		ec.Errorf("failed to store pathNoCache, %v, %v", ent.No, err)
+	}
+	if policy.IsUrlPattern(ent.Url) {
+		evictUrlPatternCache(ec, ent.Method)
+	}
+	loadOnePathResCacheAsync(ec, ent.No)
+	return evictDenyOfRoles(ec, denyRoleNos(denies))
+}
+
+func restoreResource(ec miso.Rail, id int) error {
+	ent, e := findTrashEntity(`select id, code 'no', del_no from resource where id = ? and is_del = 1 and del_no != ''`, id)
+	if e != nil {
+		return e
+	}
+
+	var denies []trashBinding
+	_, e = lockResourceGlobal(ec, func() (any, error) {
+		var liveId int
+		if t := miso.GetMySQL().Raw(`select id from resource where code = ? and is_del = 0 limit 1`, ent.No).Scan(&liveId); t.Error != nil {
+			return nil, t.Error
+		}
+
+		return nil, miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+			if e := restoreTrashEntity(tx, "resource", ent.Id, liveId); e != nil {
+				return e
+			}
+
+			roleRes, e := findTrashBindings(tx, `select rr.id, rr.role_no, rr.res_code from role_resource rr
+				where rr.res_code = ? and rr.is_del = 1 and rr.del_no = ?
+				and exists (select * from role ro where ro.role_no = rr.role_no and ro.is_del = 0)
+				and not exists (select * from role_resource x where x.role_no = rr.role_no and x.res_code = rr.res_code and x.is_del = 0)`,
+				ent.No, ent.DelNo)
+			if e != nil {
+				return e
+			}
+			if e := restoreBindings(tx, "role_resource", roleRes); e != nil {
+				return e
+			}
+
+			pathRes, e := findTrashBindings(tx, `select pr.id, pr.path_no, pr.res_code from path_resource pr
+				where pr.res_code = ? and pr.is_del = 1 and pr.del_no = ?
+				and exists (select * from path p where p.path_no = pr.path_no and p.is_del = 0)
+				and not exists (select * from path_resource x where x.path_no = pr.path_no and x.res_code = pr.res_code and x.is_del = 0)`,
+				ent.No, ent.DelNo)
+			if e != nil {
+				return e
+			}
+			if e := restoreBindings(tx, "path_resource", pathRes); e != nil {
+				return e
+			}
+
+			denies, e = findTrashBindings(tx, `select rd.id, rd.role_no, rd.res_code, rd.path_no from role_deny rd
+				where rd.res_code = ? and rd.is_del = 1 and rd.del_no = ?
+				and exists (select * from role ro where ro.role_no = rd.role_no and ro.is_del = 0)
+				and not exists (select * from role_deny x where x.role_no = rd.role_no and x.res_code = rd.res_code and x.path_no = rd.path_no and x.is_del = 0)`,
+				ent.No, ent.DelNo)
+			if e != nil {
+				return e
+			}
+			return restoreBindings(tx, "role_deny", denies)
+		})
+	})
+	if e != nil {
+		return e
+	}
+	ec.Infof("Restored resource %s", ent.No)
+
+	if err := resCodeCache.Put(ec, ent.No, "1"); err != nil {
+		ec.Errorf("failed to load resCodeCache, %v, %v", ent.No, err)
+	}
+	publishAllChange(ec)
+
+	// asynchronously reload the cache of paths and resources
+	go func() {
+		if e := LoadPathResCache(ec); e != nil {
+			ec.Errorf("Failed to load path resource cache, %v", e)
+		}
+	}()
+	// asynchronously reload the cache of role and resources
+	go func() {
+		if e := LoadRoleResCache(ec); e != nil {
+			ec.Errorf("Failed to load role resource cache, %v", e)
+		}
+	}()
+	return evictDenyOfRoles(ec, denyRoleNos(denies))
+}
+
+func restoreRole(ec miso.Rail, id int) error {
+	ent, e := findTrashEntity(`select id, role_no 'no', del_no from role where id = ? and is_del = 1 and del_no != ''`, id)
+	if e != nil {
+		return e
+	}
+
+	_, e = miso.RLockRun(ec, "goauth:role:"+ent.No, func() (any, error) { // lock for role
+		return lockRoleParent(ec, func() (any, error) {
+			var liveId int
+			if t := miso.GetMySQL().Raw(`select id from role where role_no = ? and is_del = 0 limit 1`, ent.No).Scan(&liveId); t.Error != nil {
+				return nil, t.Error
+			}
+			if liveId > 0 {
+				return nil, miso.NewErrCode(ErrCodeIllegalState, "Role already exists")
+			}
+
+			e := miso.GetMySQL().Transaction(func(tx *gorm.DB) error {
+				if e := restoreTrashEntity(tx, "role", ent.Id, 0); e != nil {
+					return e
+				}
+
+				roleRes, e := findTrashBindings(tx, `select rr.id, rr.role_no, rr.res_code from role_resource rr
+					where rr.role_no = ? and rr.is_del = 1 and rr.del_no = ?
+					and exists (select * from resource r where `+roleResMatchCond+` and r.is_del = 0)
+					and not exists (select * from role_resource x where x.role_no = rr.role_no and x.res_code = rr.res_code and x.is_del = 0)`,
+					ent.No, ent.DelNo)
+				if e != nil {
+					return e
+				}
+				if e := restoreBindings(tx, "role_resource", roleRes); e != nil {
+					return e
+				}
+
+				denies, e := findTrashBindings(tx, `select rd.id, rd.role_no, rd.res_code, rd.path_no from role_deny rd
+					where rd.role_no = ? and rd.is_del = 1 and rd.del_no = ?
+					and (rd.res_code = '' or exists (select * from resource r where r.code = rd.res_code and r.is_del = 0))
+					and (rd.path_no = '' or exists (select * from path p where p.path_no = rd.path_no and p.is_del = 0))
+					and not exists (select * from role_deny x where x.role_no = rd.role_no and x.res_code = rd.res_code and x.path_no = rd.path_no and x.is_del = 0)`,
+					ent.No, ent.DelNo)
+				if e != nil {
+					return e
+				}
+				return restoreBindings(tx, "role_deny", denies)
+			})
+			if e != nil {
+				return nil, e
+			}
+
+			// the inheritance is restored one by one, the ones that would be cyclic are skipped
+			parents, e := findTrashBindings(miso.GetMySQL(), `select rp.id, rp.role_no, rp.parent_role_no from role_parent rp
+				where (rp.role_no = ? or rp.parent_role_no = ?) and rp.is_del = 1 and rp.del_no = ?
+				and exists (select * from role ro where ro.role_no = if(rp.role_no = ?, rp.parent_role_no, rp.role_no) and ro.is_del = 0)
+				and not exists (select * from role_parent x where x.role_no = rp.role_no and x.parent_role_no = rp.parent_role_no and x.is_del = 0)`,
+				ent.No, ent.No, ent.DelNo, ent.No)
+			if e != nil {
+				return nil, e
+			}
+			for _, p := range parents {
+				if isAdminRole(p.ParentRoleNo) {
+					ec.Infof("Role '%s' is an administrator role, inheritance is not restored", p.ParentRoleNo)
+					continue
+				}
+				ancestors, e := listAncestorRoleNos(p.ParentRoleNo)
+				if e != nil {
+					return nil, e
+				}
+				if containsStr(ancestors, p.RoleNo) {
+					ec.Infof("Role '%s' is an ancestor of role '%s', inheritance is not restored", p.RoleNo, p.ParentRoleNo)
+					continue
+				}
+				if e := restoreBindings(miso.GetMySQL(), "role_parent", []trashBinding{p}); e != nil {
+					return nil, e
+				}
+			}
+			return nil, nil
+		})
+	})
+	if e != nil {
+		return e
+	}
+	ec.Infof("Restored role %s", ent.No)
+
+	if e := roleInfoCache.Del(ec, ent.No); e != nil {
+		return e
+	}
+	if e := evictDenyOfRoleTree(ec, ent.No); e != nil {
+		return e
+	}
+	return refreshResOfRoleTree(ec, ent.No, nil)
+}
+
+func denyRoleNos(denies []trashBinding) []string {
+	roleNos := make([]string, 0, len(denies))
+	for _, d := range denies {
+		roleNos = append(roleNos, d.RoleNo)
+	}
+	return roleNos
+}
+
+// Evict cached deny rules of the roles whose deny rules are deleted in the deletion batch
+func evictDenyOfDelNo(ec miso.Rail, delNo string) error {
+	var roleNos []string
+	if t := miso.GetMySQL().Raw(`select distinct role_no from role_deny where del_no = ?`, delNo).Scan(&roleNos); t.Error != nil {
+		return t.Error
+	}
+	return evictDenyOfRoles(ec, roleNos)
+}
+
+// Evict cached deny rules of the roles and their descendants
+func evictDenyOfRoles(ec miso.Rail, roleNos []string) error {
+	seen := map[string]struct{}{}
+	for _, r := range roleNos {
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		if e := evictDenyOfRoleTree(ec, r); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func containsStr(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package goauth
+
+import (
+	"testing"
+
+	"github.com/curtisnewbie/miso/miso"
+)
+
+func TestListTrash(t *testing.T) {
+	before(t)
+
+	for _, typ := range []string{TrashTypePath, TrashTypeResource, TrashTypeRole} {
+		resp, e := ListTrash(miso.EmptyRail(), ListTrashReq{Type: typ, Paging: miso.Paging{Page: 1, Limit: 10}})
+		if e != nil {
+			t.Fatal(e)
+		}
+		t.Logf("%s: %+v", typ, resp)
+	}
+
+	if _, e := ListTrash(miso.EmptyRail(), ListTrashReq{Type: "unknown"}); e == nil {
+		t.Fatal("expected error for unknown trash type")
+	}
+}